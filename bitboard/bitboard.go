@@ -0,0 +1,110 @@
+package bitboard
+
+import (
+	"math/bits"
+	"strings"
+)
+
+type Bitboard uint64
+
+const (
+	NoSquare = -1
+
+	fileA Bitboard = 0x0101010101010101
+	fileH Bitboard = fileA << 7
+	rank1 Bitboard = 0xff
+	rank8 Bitboard = rank1 << 56
+)
+
+var (
+	knightAttacks [64]Bitboard
+	kingAttacks   [64]Bitboard
+	pawnAttacks   [2][64]Bitboard
+	squareNames   [64]string
+)
+
+func init() {
+	for sq := 0; sq < 64; sq++ {
+		squareNames[sq] = string(rune('a'+sq%8)) + string(rune('1'+sq/8))
+
+		knightAttacks[sq] = stepAttacks(sq, [][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}})
+		kingAttacks[sq] = stepAttacks(sq, [][2]int{{0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}, {-1, 0}, {-1, 1}})
+		pawnAttacks[white][sq] = stepAttacks(sq, [][2]int{{-1, 1}, {1, 1}})
+		pawnAttacks[black][sq] = stepAttacks(sq, [][2]int{{-1, -1}, {1, -1}})
+	}
+
+	initMagics()
+	initZobrist()
+}
+
+func SquareBB(sq int) Bitboard {
+	return 1 << sq
+}
+
+// KingAttacks is the squares next to sq
+func KingAttacks(sq int) Bitboard {
+	return kingAttacks[sq]
+}
+
+func (b Bitboard) Has(sq int) bool {
+	return b&SquareBB(sq) != 0
+}
+
+func (b Bitboard) Count() int {
+	return bits.OnesCount64(uint64(b))
+}
+
+func (b Bitboard) LSB() int {
+	return bits.TrailingZeros64(uint64(b))
+}
+
+func (b *Bitboard) PopLSB() int {
+	sq := b.LSB()
+	*b &= *b - 1
+	return sq
+}
+
+func (b Bitboard) String() string {
+	var sb strings.Builder
+	for rank := 7; rank >= 0; rank-- {
+		for file := 0; file < 8; file++ {
+			if b.Has(rank*8 + file) {
+				sb.WriteString("1")
+			} else {
+				sb.WriteString(".")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func SquareName(sq int) string {
+	if sq == NoSquare {
+		return "-"
+	}
+
+	return squareNames[sq]
+}
+
+func ParseSquare(name string) int {
+	if len(name) != 2 || name[0] < 'a' || name[0] > 'h' || name[1] < '1' || name[1] > '8' {
+		return NoSquare
+	}
+
+	return int(name[1]-'1')*8 + int(name[0]-'a')
+}
+
+func stepAttacks(sq int, steps [][2]int) Bitboard {
+	var attacks Bitboard
+	file, rank := sq%8, sq/8
+
+	for _, step := range steps {
+		f, r := file+step[0], rank+step[1]
+		if f >= 0 && f < 8 && r >= 0 && r < 8 {
+			attacks |= SquareBB(r*8 + f)
+		}
+	}
+
+	return attacks
+}
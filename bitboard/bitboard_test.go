@@ -0,0 +1,85 @@
+package bitboard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+func positionFromFEN(fen string, opts ...func(*Position)) Position {
+	fields := strings.Fields(fen)
+
+	activeColor := piece.White
+	if fields[1] == "b" {
+		activeColor = piece.Black
+	}
+
+	var castling uint8
+	for _, c := range fields[2] {
+		switch c {
+		case 'K':
+			castling |= WhiteKingside
+		case 'Q':
+			castling |= WhiteQueenside
+		case 'k':
+			castling |= BlackKingside
+		case 'q':
+			castling |= BlackQueenside
+		}
+	}
+
+	return NewPosition(board.LoadFEN(fields[0]), activeColor, castling, ParseSquare(fields[3]), opts...)
+}
+
+func TestSquareNames(t *testing.T) {
+	assert.Equal(t, "a1", SquareName(0))
+	assert.Equal(t, "h8", SquareName(63))
+	assert.Equal(t, "-", SquareName(NoSquare))
+	assert.Equal(t, 28, ParseSquare("e4"))
+	assert.Equal(t, NoSquare, ParseSquare("-"))
+	assert.Equal(t, NoSquare, ParseSquare("i9"))
+}
+
+func TestLeaperAttacks(t *testing.T) {
+	assert.Equal(t, 8, knightAttacks[ParseSquare("e4")].Count())
+	assert.Equal(t, 2, knightAttacks[ParseSquare("a1")].Count())
+	assert.Equal(t, 8, kingAttacks[ParseSquare("e4")].Count())
+	assert.Equal(t, 3, kingAttacks[ParseSquare("h8")].Count())
+	assert.Equal(t, SquareBB(ParseSquare("d5"))|SquareBB(ParseSquare("f5")), pawnAttacks[white][ParseSquare("e4")])
+	assert.Equal(t, SquareBB(ParseSquare("b3")), pawnAttacks[black][ParseSquare("a4")])
+}
+
+func TestSlidingAttacksMatchRayWalk(t *testing.T) {
+	rng := xorshift(99)
+	for i := 0; i < 1000; i++ {
+		occupied := Bitboard(rng.next() & rng.next())
+		for sq := 0; sq < 64; sq++ {
+			assert.Equal(t, slidingAttacks(sq, occupied, rookDirections), RookAttacks(sq, occupied))
+			assert.Equal(t, slidingAttacks(sq, occupied, bishopDirections), BishopAttacks(sq, occupied))
+		}
+	}
+}
+
+func TestMakeMove(t *testing.T) {
+	p := positionFromFEN("r3k2r/8/8/8/4p3/8/3P4/R3K2R w KQkq - 0 1")
+
+	p.MakeMove(Move{From: ParseSquare("d2"), To: ParseSquare("d4"), flags: flagDoublePush})
+	assert.Equal(t, ParseSquare("d3"), p.EnPassant())
+	assert.Equal(t, piece.Black, p.SideToMove())
+
+	p.MakeMove(Move{From: ParseSquare("e4"), To: ParseSquare("d3"), flags: flagCapture | flagEnPassant})
+	assert.Equal(t, piece.Empty, p.Piece(ParseSquare("d4")))
+	assert.Equal(t, piece.Pawn*piece.Black, p.Piece(ParseSquare("d3")))
+
+	p.MakeMove(Move{From: ParseSquare("e1"), To: ParseSquare("c1"), flags: flagCastle})
+	assert.Equal(t, piece.King*piece.White, p.Piece(ParseSquare("c1")))
+	assert.Equal(t, piece.Rook*piece.White, p.Piece(ParseSquare("d1")))
+	assert.Equal(t, BlackKingside|BlackQueenside, p.Castling())
+
+	p.MakeMove(Move{From: ParseSquare("h8"), To: ParseSquare("h1"), flags: flagCapture})
+	assert.Equal(t, BlackQueenside, p.Castling())
+	assert.Equal(t, 1, p.Pieces(piece.White, piece.Rook).Count())
+}
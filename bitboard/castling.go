@@ -0,0 +1,139 @@
+package bitboard
+
+import (
+	"github.com/ethansaxenian/chess/assert"
+	"github.com/ethansaxenian/chess/piece"
+)
+
+const (
+	WhiteKingside uint8 = 1 << iota
+	WhiteQueenside
+	BlackKingside
+	BlackQueenside
+)
+
+type castle struct {
+	right      uint8
+	king       int
+	target     int
+	rook       int
+	rookTarget int
+	// squares that must be empty, apart from the king and rook themselves
+	empty Bitboard
+	// squares the king crosses, which must not be attacked
+	traverse []int
+}
+
+// CastlingSquares is where one side's king and rooks start
+type CastlingSquares struct {
+	King, KingsideRook, QueensideRook int
+}
+
+// CastlingSetup is where the kings and rooks castle from. it is the same for
+// every standard game, but differs between chess960 start positions, where
+// castling is also written as the king taking its own rook
+type CastlingSetup struct {
+	chess960 bool
+	castles  [2][2]castle
+	// ANDed with the castling rights whenever a move touches a square
+	mask [64]uint8
+}
+
+var StandardCastling = NewCastlingSetup(
+	CastlingSquares{King: 4, KingsideRook: 7, QueensideRook: 0},
+	CastlingSquares{King: 60, KingsideRook: 63, QueensideRook: 56},
+	false,
+)
+
+// NewCastlingSetup takes each side's squares on its own back rank. the king
+// always ends up on the g or c file and the rook next to it, as in standard
+// chess
+func NewCastlingSetup(white, black CastlingSquares, chess960 bool) *CastlingSetup {
+	c := &CastlingSetup{chess960: chess960}
+
+	for sq := range c.mask {
+		c.mask[sq] = WhiteKingside | WhiteQueenside | BlackKingside | BlackQueenside
+	}
+
+	rights := [2][2]uint8{{WhiteKingside, WhiteQueenside}, {BlackKingside, BlackQueenside}}
+	for side, squares := range [2]CastlingSquares{white, black} {
+		backRank := squares.King / 8 * 8
+
+		c.castles[side] = [2]castle{
+			newCastle(rights[side][0], squares.King, squares.KingsideRook, backRank+6, backRank+5),
+			newCastle(rights[side][1], squares.King, squares.QueensideRook, backRank+2, backRank+3),
+		}
+
+		c.mask[squares.King] &^= rights[side][0] | rights[side][1]
+		c.mask[squares.KingsideRook] &^= rights[side][0]
+		c.mask[squares.QueensideRook] &^= rights[side][1]
+	}
+
+	return c
+}
+
+// squaresBetween includes both ends, which are on the same rank
+func squaresBetween(a, b int) []int {
+	step := 1
+	if b < a {
+		step = -1
+	}
+
+	squares := []int{a}
+	for sq := a; sq != b; {
+		sq += step
+		squares = append(squares, sq)
+	}
+
+	return squares
+}
+
+func newCastle(right uint8, king, rook, target, rookTarget int) castle {
+	c := castle{right: right, king: king, target: target, rook: rook, rookTarget: rookTarget}
+
+	c.traverse = squaresBetween(king, target)
+	for _, sq := range c.traverse {
+		c.empty |= SquareBB(sq)
+	}
+	for _, sq := range squaresBetween(rook, rookTarget) {
+		c.empty |= SquareBB(sq)
+	}
+	c.empty &^= SquareBB(king) | SquareBB(rook)
+
+	return c
+}
+
+func (c *CastlingSetup) Chess960() bool {
+	return c.chess960
+}
+
+// King is the square color's king castles from
+func (c *CastlingSetup) King(color piece.Piece) int {
+	return c.castles[colorIndex(color)][0].king
+}
+
+// Rook is the square color's rook castles from on one side
+func (c *CastlingSetup) Rook(color piece.Piece, side piece.Side) int {
+	return c.castles[colorIndex(color)][side].rook
+}
+
+// to is where a castling move says the king goes
+func (c *CastlingSetup) to(cs castle) int {
+	if c.chess960 {
+		return cs.rook
+	}
+
+	return cs.target
+}
+
+// castleFor finds the castle a move stands for
+func (c *CastlingSetup) castleFor(side int, m Move) castle {
+	for _, cs := range c.castles[side] {
+		if m.From == cs.king && m.To == c.to(cs) {
+			return cs
+		}
+	}
+
+	assert.Raise("bitboard: not a castle: " + m.String())
+	return castle{}
+}
@@ -0,0 +1,86 @@
+package bitboard
+
+import (
+	"github.com/ethansaxenian/chess/piece"
+)
+
+// WithPockets plays crazyhouse, where captured pieces can be dropped back on
+// the board. promoted are the pieces that become pawns again when captured
+func WithPockets(white, black piece.Pocket, promoted Bitboard) func(*Position) {
+	return func(p *Position) {
+		p.crazyhouse = true
+		p.pockets = [2]piece.Pocket{white, black}
+		p.promoted = promoted
+	}
+}
+
+func (p Position) Crazyhouse() bool {
+	return p.crazyhouse
+}
+
+func (p Position) Pocket(color piece.Piece) piece.Pocket {
+	return p.pockets[colorIndex(color)]
+}
+
+func (p Position) Promoted() Bitboard {
+	return p.promoted
+}
+
+func (p *Position) addToPocket(side int, pieceType piece.Piece) {
+	p.pockets[side][pieceType]++
+	p.hash ^= ZobristPocket(pieceType*colorPiece(side), p.pockets[side][pieceType])
+}
+
+func (p *Position) takeFromPocket(side int, pieceType piece.Piece) {
+	pieceType = pieceType.Type()
+	p.hash ^= ZobristPocket(pieceType*colorPiece(side), p.pockets[side][pieceType])
+	p.pockets[side][pieceType]--
+}
+
+// pocketCapture runs before m is made, and pockets whatever it captures
+func (p *Position) pocketCapture(m Move, forward int) {
+	if m.IsDrop() {
+		return
+	}
+
+	captured := m.To
+	if m.flags&flagEnPassant != 0 {
+		captured = m.To - forward
+	}
+
+	// a chess960 castle lands on its own rook
+	if pc := p.squares[captured]; pc != piece.Empty && !m.IsCastle() {
+		pieceType := pc.Type()
+		if p.promoted.Has(captured) {
+			pieceType = piece.Pawn
+		}
+		p.addToPocket(p.side, pieceType)
+	}
+
+	promoted := p.promoted.Has(m.From) || m.Promotion != piece.Empty
+	p.promoted &^= SquareBB(m.From) | SquareBB(captured)
+	if promoted {
+		p.promoted |= SquareBB(m.To)
+	}
+}
+
+func (p *Position) generateDrops(moves []Move, targets Bitboard) []Move {
+	pocket := p.pockets[p.side]
+
+	for pieceType := piece.Pawn; pieceType < piece.King; pieceType++ {
+		if pocket[pieceType] == 0 {
+			continue
+		}
+
+		squares := targets
+		if pieceType == piece.Pawn {
+			squares &^= rank1 | rank8
+		}
+
+		for squares != 0 {
+			moves = append(moves, Move{From: NoSquare, To: squares.PopLSB(), Drop: pieceType})
+		}
+	}
+
+	return moves
+}
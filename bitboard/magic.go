@@ -0,0 +1,152 @@
+package bitboard
+
+var (
+	rookDirections   = [][2]int{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+	bishopDirections = [][2]int{{1, 1}, {1, -1}, {-1, -1}, {-1, 1}}
+)
+
+type magic struct {
+	mask    Bitboard
+	magic   uint64
+	shift   uint
+	attacks []Bitboard
+}
+
+func (m *magic) index(occupied Bitboard) uint64 {
+	return uint64(occupied&m.mask) * m.magic >> m.shift
+}
+
+var (
+	rookMagics   [64]magic
+	bishopMagics [64]magic
+)
+
+// between holds the squares strictly between two squares on a shared line
+var between [64][64]Bitboard
+
+func RookAttacks(sq int, occupied Bitboard) Bitboard {
+	m := &rookMagics[sq]
+	return m.attacks[m.index(occupied)]
+}
+
+func BishopAttacks(sq int, occupied Bitboard) Bitboard {
+	m := &bishopMagics[sq]
+	return m.attacks[m.index(occupied)]
+}
+
+func QueenAttacks(sq int, occupied Bitboard) Bitboard {
+	return RookAttacks(sq, occupied) | BishopAttacks(sq, occupied)
+}
+
+// slidingAttacks walks each ray until it leaves the board or hits a blocker
+func slidingAttacks(sq int, occupied Bitboard, directions [][2]int) Bitboard {
+	var attacks Bitboard
+
+	for _, d := range directions {
+		f, r := sq%8+d[0], sq/8+d[1]
+		for f >= 0 && f < 8 && r >= 0 && r < 8 {
+			target := r*8 + f
+			attacks |= SquareBB(target)
+			if occupied.Has(target) {
+				break
+			}
+			f, r = f+d[0], r+d[1]
+		}
+	}
+
+	return attacks
+}
+
+func relevantOccupancy(sq int, directions [][2]int) Bitboard {
+	var edges Bitboard
+	if sq/8 != 0 {
+		edges |= rank1
+	}
+	if sq/8 != 7 {
+		edges |= rank8
+	}
+	if sq%8 != 0 {
+		edges |= fileA
+	}
+	if sq%8 != 7 {
+		edges |= fileH
+	}
+
+	return slidingAttacks(sq, 0, directions) &^ edges
+}
+
+// xorshift is a fixed-seed generator so the magics are the same on every run
+type xorshift uint64
+
+func (x *xorshift) next() uint64 {
+	*x ^= *x >> 12
+	*x ^= *x << 25
+	*x ^= *x >> 27
+	return uint64(*x) * 2685821657736338717
+}
+
+func (x *xorshift) sparse() uint64 {
+	return x.next() & x.next() & x.next()
+}
+
+func findMagic(sq int, directions [][2]int, rng *xorshift) magic {
+	mask := relevantOccupancy(sq, directions)
+	bits := mask.Count()
+
+	var occupancies, references []Bitboard
+	occupied := Bitboard(0)
+	for {
+		occupancies = append(occupancies, occupied)
+		references = append(references, slidingAttacks(sq, occupied, directions))
+
+		occupied = (occupied - mask) & mask
+		if occupied == 0 {
+			break
+		}
+	}
+
+	m := magic{mask: mask, shift: uint(64 - bits), attacks: make([]Bitboard, 1<<bits)}
+	epochs := make([]int, 1<<bits)
+
+	for epoch := 1; ; epoch++ {
+		m.magic = rng.sparse()
+		if Bitboard((uint64(mask)*m.magic)>>56).Count() < 6 {
+			continue
+		}
+
+		ok := true
+		for i, occ := range occupancies {
+			idx := m.index(occ)
+			if epochs[idx] < epoch {
+				epochs[idx] = epoch
+				m.attacks[idx] = references[i]
+			} else if m.attacks[idx] != references[i] {
+				ok = false
+				break
+			}
+		}
+
+		if ok {
+			return m
+		}
+	}
+}
+
+func initMagics() {
+	rng := xorshift(728)
+
+	for sq := 0; sq < 64; sq++ {
+		rookMagics[sq] = findMagic(sq, rookDirections, &rng)
+		bishopMagics[sq] = findMagic(sq, bishopDirections, &rng)
+	}
+
+	for a := 0; a < 64; a++ {
+		for b := 0; b < 64; b++ {
+			for _, directions := range [][][2]int{rookDirections, bishopDirections} {
+				if slidingAttacks(a, 0, directions).Has(b) {
+					between[a][b] = slidingAttacks(a, SquareBB(b), directions) & slidingAttacks(b, SquareBB(a), directions)
+				}
+			}
+		}
+	}
+}
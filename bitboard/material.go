@@ -0,0 +1,136 @@
+package bitboard
+
+import (
+	"github.com/ethansaxenian/chess/piece"
+)
+
+// b1, d1 and so on, since a1 is a dark square
+const lightSquares Bitboard = 0x55aa55aa55aa55aa
+
+// InsufficientMaterial reports whether neither side has the material to ever
+// checkmate: bare kings, a single minor piece, or only bishops that all
+// travel on the same color. crazyhouse pockets count as material, since
+// anything in them can be dropped
+func (p Position) InsufficientMaterial() bool {
+	for _, side := range []int{white, black} {
+		if p.pieces[side][piece.Pawn]|p.pieces[side][piece.Rook]|p.pieces[side][piece.Queen] != 0 || !p.pockets[side].Empty() {
+			return false
+		}
+	}
+
+	knights := p.pieces[white][piece.Knight] | p.pieces[black][piece.Knight]
+	bishops := p.pieces[white][piece.Bishop] | p.pieces[black][piece.Bishop]
+
+	return minorsCantMate(knights, bishops)
+}
+
+// CanMate reports whether some series of legal moves, however unlikely, ends
+// with color mating the other side as its material stands. a lone knight
+// needs something besides queens to hem the king in, and bishops on one color
+// need a pawn, a knight or a bishop on the other color to block with
+func (p Position) CanMate(color piece.Piece) bool {
+	us, them := colorIndex(color), colorIndex(color)^1
+	if p.pieces[us][piece.Pawn]|p.pieces[us][piece.Rook]|p.pieces[us][piece.Queen] != 0 || !p.pockets[us].Empty() {
+		return true
+	}
+
+	// anything in the other side's pocket can be dropped in the king's way
+	if !p.pockets[them].Empty() {
+		return true
+	}
+
+	knights, bishops := p.pieces[us][piece.Knight], p.pieces[us][piece.Bishop]
+	switch {
+	case knights|bishops == 0:
+		return false
+	case knights != 0 && bishops != 0, knights.Count() > 1:
+		return true
+	case knights != 0:
+		return p.occupied[them]&^(p.pieces[them][piece.King]|p.pieces[them][piece.Queen]) != 0
+	case bishops&lightSquares != 0 && bishops&^lightSquares != 0:
+		return true
+	}
+
+	otherColor := lightSquares
+	if bishops&lightSquares != 0 {
+		otherColor = ^lightSquares
+	}
+
+	return p.pieces[them][piece.Pawn]|p.pieces[them][piece.Knight]|p.pieces[them][piece.Bishop]&otherColor != 0
+}
+
+// minorsCantMate is whether kings and these knights and bishops alone can
+// never give mate
+func minorsCantMate(knights, bishops Bitboard) bool {
+	if (knights | bishops).Count() <= 1 {
+		return true
+	}
+
+	return knights == 0 && (bishops&lightSquares == 0 || bishops&^lightSquares == 0)
+}
+
+// BlockedPawnsDeadPosition is a heuristic for the most common dead position: only
+// kings and pawns are left, every pawn is blocked by another pawn with nothing
+// to capture, and neither king can reach an enemy pawn. pawns then never move,
+// and a king can never be put in check
+func (p Position) BlockedPawnsDeadPosition() bool {
+	pawns := p.pieces[white][piece.Pawn] | p.pieces[black][piece.Pawn]
+	kings := p.pieces[white][piece.King] | p.pieces[black][piece.King]
+	if pawns == 0 || p.Occupied() != pawns|kings || !p.pockets[white].Empty() || !p.pockets[black].Empty() {
+		return false
+	}
+
+	var attacked [2]Bitboard
+	for _, side := range []int{white, black} {
+		forward := 8
+		if side == black {
+			forward = -8
+		}
+
+		ours := p.pieces[side][piece.Pawn]
+		for ours != 0 {
+			sq := ours.PopLSB()
+
+			if !pawns.Has(sq + forward) {
+				return false
+			}
+
+			if pawnAttacks[side][sq]&p.pieces[side^1][piece.Pawn] != 0 {
+				return false
+			}
+
+			attacked[side] |= pawnAttacks[side][sq]
+		}
+	}
+
+	for _, side := range []int{white, black} {
+		if p.pieces[side][piece.King] == 0 {
+			continue
+		}
+
+		allowed := ^(p.pieces[side][piece.Pawn] | attacked[side^1])
+		if p.kingRegion(p.king(side), allowed)&p.pieces[side^1][piece.Pawn] != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// kingRegion flood fills every square a king could eventually walk to
+func (p Position) kingRegion(from int, allowed Bitboard) Bitboard {
+	region := SquareBB(from)
+	frontier := region
+
+	for frontier != 0 {
+		var next Bitboard
+		for frontier != 0 {
+			next |= kingAttacks[frontier.PopLSB()]
+		}
+
+		frontier = next & allowed &^ region
+		region |= frontier
+	}
+
+	return region
+}
@@ -0,0 +1,99 @@
+package bitboard
+
+import (
+	"testing"
+
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsufficientMaterial(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		expected bool
+	}{
+		"bare kings":                {"8/8/4k3/8/8/3K4/8/8 w - - 0 1", true},
+		"king and bishop":           {"8/8/4k3/8/8/3K4/8/5B2 w - - 0 1", true},
+		"king and knight":           {"8/8/4k3/8/8/3K4/8/5n2 w - - 0 1", true},
+		"same colored bishops":      {"8/8/4k1b1/8/8/3K4/8/5B2 w - - 0 1", true},
+		"many same colored bishops": {"8/1b6/4k1b1/8/8/3K4/8/1B3B2 w - - 0 1", true},
+		"opposite colored bishops":  {"8/8/4kb2/8/8/3K4/8/5B2 w - - 0 1", false},
+		"two knights":               {"8/8/4k3/8/8/3K4/8/4NN2 w - - 0 1", false},
+		"knight against knight":     {"8/8/4kn2/8/8/3K4/8/5N2 w - - 0 1", false},
+		"bishop and knight":         {"8/8/4k3/8/8/3K4/8/4BN2 w - - 0 1", false},
+		"pawn":                      {"8/8/4k3/8/8/3K4/4P3/8 w - - 0 1", false},
+		"rook":                      {"8/8/4k3/8/8/3K4/8/4r3 w - - 0 1", false},
+		"starting position":         {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := positionFromFEN(test.fen)
+			assert.Equal(t, test.expected, p.InsufficientMaterial())
+		})
+	}
+}
+
+func TestLightSquares(t *testing.T) {
+	for _, sq := range []string{"b1", "h1", "a2", "a8"} {
+		assert.True(t, lightSquares.Has(ParseSquare(sq)), sq)
+	}
+	for _, sq := range []string{"a1", "g1", "b2", "h8"} {
+		assert.False(t, lightSquares.Has(ParseSquare(sq)), sq)
+	}
+}
+
+func TestCanMate(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		expected bool
+	}{
+		"bare king":                          {"8/8/4k3/8/8/3K4/8/8 w - - 0 1", false},
+		"knight against a bare king":         {"8/8/4k3/8/8/3K4/8/5N2 w - - 0 1", false},
+		"knight against a pawn":              {"8/8/4k3/4p3/8/3K4/8/5N2 w - - 0 1", true},
+		"knight against queens":              {"8/8/4k3/8/8/3K4/8/q4N1q w - - 0 1", false},
+		"knight against a rook":              {"8/8/4k3/8/8/3K4/8/r4N2 w - - 0 1", true},
+		"two knights":                        {"8/8/4k3/8/8/3K4/8/4NN2 w - - 0 1", true},
+		"bishop against a bare king":         {"8/8/4k3/8/8/3K4/8/5B2 w - - 0 1", false},
+		"bishop against a pawn":              {"8/8/4k3/4p3/8/3K4/8/5B2 w - - 0 1", true},
+		"bishop against a knight":            {"8/8/4k3/8/8/3K4/8/n4B2 w - - 0 1", true},
+		"bishop against a rook":              {"8/8/4k3/8/8/3K4/8/r4B2 w - - 0 1", false},
+		"bishop against a same color bishop": {"8/8/4k3/8/8/3K4/8/1b3B2 w - - 0 1", false},
+		"bishop against an opposite bishop":  {"8/8/4k3/8/8/3K4/8/b4B2 w - - 0 1", true},
+		"same colored bishops":               {"8/8/4k3/8/8/3K4/8/3B1B2 w - - 0 1", false},
+		"opposite colored bishops":           {"8/8/4k3/8/8/3K4/8/4BB2 w - - 0 1", true},
+		"bishop and knight":                  {"8/8/4k3/8/8/3K4/8/4BN2 w - - 0 1", true},
+		"rook":                               {"8/8/4k3/8/8/3K4/8/5R2 w - - 0 1", true},
+		"pawn":                               {"8/8/4k3/8/8/3K4/4P3/8 w - - 0 1", true},
+		"only the other side's pieces":       {"8/8/4k3/8/8/3K4/8/3qr3 w - - 0 1", false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := positionFromFEN(test.fen)
+			assert.Equal(t, test.expected, p.CanMate(piece.White))
+		})
+	}
+}
+
+func TestBlockedPawnsDeadPosition(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		expected bool
+	}{
+		"locked chain":            {"8/4k3/8/p1p1p1p1/P1P1P1P1/8/4K3/8 w - - 0 1", true},
+		"king reaches a pawn":     {"8/4k3/8/p1p1p3/P1P1P3/8/4K3/8 w - - 0 1", false},
+		"pawn can capture":        {"8/4k3/8/p1p1p1p1/P1P1P1PP/8/4K3/8 w - - 0 1", false},
+		"free pawn":               {"8/4k3/8/p1p1p1p1/P1P1P1P1/8/4K2P/8 w - - 0 1", false},
+		"extra piece":             {"8/4k3/8/p1p1p1p1/P1P1P1P1/8/4K3/7N w - - 0 1", false},
+		"no pawns":                {"8/4k3/8/8/8/8/4K3/8 w - - 0 1", false},
+		"defended pawns in reach": {"8/8/3k4/1p1p1p1p/1P1P1P1P/8/8/K7 w - - 0 1", true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := positionFromFEN(test.fen)
+			assert.Equal(t, test.expected, p.BlockedPawnsDeadPosition())
+		})
+	}
+}
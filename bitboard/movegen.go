@@ -0,0 +1,323 @@
+package bitboard
+
+import (
+	"github.com/ethansaxenian/chess/piece"
+)
+
+var promotionPieces = []piece.Piece{piece.Knight, piece.Bishop, piece.Rook, piece.Queen}
+
+func addPawnMoves(moves []Move, from, to int, flags uint8) []Move {
+	if to/8 == 0 || to/8 == 7 {
+		for _, promotion := range promotionPieces {
+			moves = append(moves, Move{From: from, To: to, Promotion: promotion, flags: flags})
+		}
+		return moves
+	}
+
+	return append(moves, Move{From: from, To: to, flags: flags})
+}
+
+func (p *Position) addTargets(moves []Move, from int, targets Bitboard) []Move {
+	them := p.occupied[p.side^1]
+
+	for targets != 0 {
+		to := targets.PopLSB()
+
+		var flags uint8
+		if them.Has(to) {
+			flags = flagCapture
+		}
+
+		moves = append(moves, Move{From: from, To: to, flags: flags})
+	}
+
+	return moves
+}
+
+func (p *Position) generatePawnMoves(moves []Move) []Move {
+	us, them := p.side, p.side^1
+	occupied := p.Occupied()
+
+	forward, startRank := 8, 1
+	if us == black {
+		forward, startRank = -8, 6
+	}
+
+	pawns := p.pieces[us][piece.Pawn]
+	for pawns != 0 {
+		from := pawns.PopLSB()
+
+		to := from + forward
+		if !occupied.Has(to) {
+			moves = addPawnMoves(moves, from, to, 0)
+
+			if from/8 == startRank && !occupied.Has(to+forward) {
+				moves = append(moves, Move{From: from, To: to + forward, flags: flagDoublePush})
+			}
+		}
+
+		captures := pawnAttacks[us][from] & p.occupied[them]
+		for captures != 0 {
+			moves = addPawnMoves(moves, from, captures.PopLSB(), flagCapture)
+		}
+
+		if p.enPassant != NoSquare && pawnAttacks[us][from].Has(p.enPassant) && p.squares[p.enPassant-forward] == piece.Pawn*colorPiece(them) {
+			moves = append(moves, Move{From: from, To: p.enPassant, flags: flagCapture | flagEnPassant})
+		}
+	}
+
+	return moves
+}
+
+func (p *Position) generateCastles(moves []Move) []Move {
+	setup := p.CastlingSetup()
+
+	for _, c := range setup.castles[p.side] {
+		if p.castling&c.right == 0 || p.Occupied()&c.empty != 0 {
+			continue
+		}
+
+		if p.squares[c.king] != piece.King*colorPiece(p.side) || p.squares[c.rook] != piece.Rook*colorPiece(p.side) {
+			continue
+		}
+
+		// no castling out of, through or into check. the castling rook may
+		// have been shielding the king's path in chess960
+		occupied := p.Occupied() &^ (SquareBB(c.king) | SquareBB(c.rook))
+		var attacked bool
+		for _, sq := range c.traverse {
+			if p.attackersOf(sq, p.side^1, occupied) != 0 {
+				attacked = true
+				break
+			}
+		}
+
+		if !attacked {
+			moves = append(moves, Move{From: c.king, To: setup.to(c), flags: flagCastle})
+		}
+	}
+
+	return moves
+}
+
+func (p *Position) PseudoLegalMoves(moves []Move) []Move {
+	us := p.side
+	occupied := p.Occupied()
+	notOurs := ^p.occupied[us]
+
+	moves = p.generatePawnMoves(moves)
+
+	for pieceType := piece.Knight; pieceType <= piece.King; pieceType++ {
+		pieces := p.pieces[us][pieceType]
+		for pieces != 0 {
+			from := pieces.PopLSB()
+
+			var targets Bitboard
+			switch pieceType {
+			case piece.Knight:
+				targets = knightAttacks[from]
+			case piece.Bishop:
+				targets = BishopAttacks(from, occupied)
+			case piece.Rook:
+				targets = RookAttacks(from, occupied)
+			case piece.Queen:
+				targets = QueenAttacks(from, occupied)
+			case piece.King:
+				targets = kingAttacks[from]
+			}
+
+			moves = p.addTargets(moves, from, targets&notOurs)
+		}
+	}
+
+	if p.crazyhouse {
+		moves = p.generateDrops(moves, ^occupied)
+	}
+
+	return p.generateCastles(moves)
+}
+
+// pins returns the pieces of side that are pinned to their king, and the
+// squares each of them may still move to without exposing it
+func (p *Position) pins(side int, king int) (Bitboard, [64]Bitboard) {
+	var pinned Bitboard
+	var rays [64]Bitboard
+
+	them := p.pieces[side^1]
+	occupied := p.Occupied()
+
+	snipers := RookAttacks(king, p.occupied[side^1])&(them[piece.Rook]|them[piece.Queen]) |
+		BishopAttacks(king, p.occupied[side^1])&(them[piece.Bishop]|them[piece.Queen])
+
+	for snipers != 0 {
+		sniper := snipers.PopLSB()
+		blockers := between[king][sniper] & occupied
+
+		if blockers.Count() == 1 && blockers&p.occupied[side] != 0 {
+			sq := blockers.LSB()
+			pinned |= blockers
+			rays[sq] = between[king][sniper] | SquareBB(sniper)
+		}
+	}
+
+	return pinned, rays
+}
+
+// enPassantIsLegal handles the one capture that removes two pieces from the
+// same rank, which can expose the king to a slider that no pin detects
+func (p *Position) enPassantIsLegal(m Move, king int) bool {
+	forward := 8
+	if p.side == black {
+		forward = -8
+	}
+
+	occupied := p.Occupied()
+	occupied &^= SquareBB(m.From) | SquareBB(m.To-forward)
+	occupied |= SquareBB(m.To)
+
+	them := p.pieces[p.side^1]
+	return RookAttacks(king, occupied)&(them[piece.Rook]|them[piece.Queen]) == 0 &&
+		BishopAttacks(king, occupied)&(them[piece.Bishop]|them[piece.Queen]) == 0
+}
+
+// EnPassantCaptures appends the side to move's en passant captures, which
+// may still leave its king in check
+func (p *Position) EnPassantCaptures(moves []Move) []Move {
+	if p.enPassant == NoSquare {
+		return moves
+	}
+
+	captured := p.enPassant - 8
+	if p.side == black {
+		captured = p.enPassant + 8
+	}
+	if p.squares[captured] != piece.Pawn*colorPiece(p.side^1) {
+		return moves
+	}
+
+	pawns := pawnAttacks[p.side^1][p.enPassant] & p.pieces[p.side][piece.Pawn]
+	for pawns != 0 {
+		moves = append(moves, Move{From: pawns.PopLSB(), To: p.enPassant, flags: flagCapture | flagEnPassant})
+	}
+
+	return moves
+}
+
+// CanCaptureEnPassant is whether the side to move has a legal en passant
+// capture, checked the way LegalMoves would without generating every move
+func (p *Position) CanCaptureEnPassant() bool {
+	candidates := p.EnPassantCaptures(nil)
+	if len(candidates) == 0 {
+		return false
+	}
+
+	us := p.side
+	if p.pieces[us][piece.King] == 0 {
+		return true
+	}
+
+	king := p.king(us)
+	checkers := p.attackersOf(king, us^1, p.Occupied())
+	if checkers.Count() > 1 {
+		return false
+	}
+
+	captured := p.enPassant - 8
+	if us == black {
+		captured = p.enPassant + 8
+	}
+	if checkers != 0 && !checkers.Has(captured) && !between[king][checkers.LSB()].Has(p.enPassant) {
+		return false
+	}
+
+	for _, m := range candidates {
+		if p.enPassantIsLegal(m, king) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Position) LegalMoves(moves []Move) []Move {
+	us := p.side
+
+	// positions without a king (as in some tests) have no notion of check
+	if p.pieces[us][piece.King] == 0 {
+		return p.PseudoLegalMoves(moves)
+	}
+
+	king := p.king(us)
+	occupied := p.Occupied()
+	checkers := p.attackersOf(king, us^1, occupied)
+	pinned, pinRays := p.pins(us, king)
+
+	evasions := ^Bitboard(0)
+	if checkers != 0 {
+		checker := checkers.LSB()
+		evasions = checkers | between[king][checker]
+	}
+
+	start := len(moves)
+	moves = p.PseudoLegalMoves(moves)
+
+	legal := moves[:start]
+	for _, m := range moves[start:] {
+		switch {
+		case m.From == king:
+			// castling already checks every square the king crosses
+			if !m.IsCastle() && p.attackersOf(m.To, us^1, occupied&^SquareBB(king)) != 0 {
+				continue
+			}
+		case checkers.Count() > 1:
+			continue
+		case m.IsDrop():
+			// a drop can only get out of check by blocking it
+			if !evasions.Has(m.To) {
+				continue
+			}
+		case m.flags&flagEnPassant != 0:
+			captured := m.To - 8
+			if us == black {
+				captured = m.To + 8
+			}
+			if !evasions.Has(m.To) && !checkers.Has(captured) {
+				continue
+			}
+			if !p.enPassantIsLegal(m, king) {
+				continue
+			}
+		default:
+			if !evasions.Has(m.To) {
+				continue
+			}
+			if pinned.Has(m.From) && !pinRays[m.From].Has(m.To) {
+				continue
+			}
+		}
+
+		legal = append(legal, m)
+	}
+
+	return legal
+}
+
+func (p *Position) Perft(depth int) int {
+	if depth == 0 {
+		return 1
+	}
+
+	moves := p.LegalMoves(make([]Move, 0, 64))
+	if depth == 1 {
+		return len(moves)
+	}
+
+	var nodes int
+	for _, m := range moves {
+		next := *p
+		next.MakeMove(m)
+		nodes += next.Perft(depth - 1)
+	}
+
+	return nodes
+}
@@ -0,0 +1,260 @@
+package bitboard
+
+import (
+	"testing"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+var perftPositions = map[string]struct {
+	fen   string
+	nodes []int
+}{
+	"start position": {
+		fen:   board.StartingFEN,
+		nodes: []int{20, 400, 8902, 197281},
+	},
+	"kiwipete": {
+		fen:   "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		nodes: []int{48, 2039, 97862},
+	},
+	"position 3": {
+		fen:   "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		nodes: []int{14, 191, 2812, 43238, 674624},
+	},
+	"position 4": {
+		fen:   "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		nodes: []int{6, 264, 9467, 422333},
+	},
+	"position 5": {
+		fen:   "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		nodes: []int{44, 1486, 62379},
+	},
+	"position 6": {
+		fen:   "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+		nodes: []int{46, 2079, 89890},
+	},
+}
+
+func TestPerft(t *testing.T) {
+	for name, test := range perftPositions {
+		t.Run(name, func(t *testing.T) {
+			for depth, nodes := range test.nodes {
+				p := positionFromFEN(test.fen)
+				assert.Equal(t, nodes, p.Perft(depth+1), "depth %d", depth+1)
+			}
+		})
+	}
+}
+
+func TestCastlingThroughCheck(t *testing.T) {
+	tests := map[string]struct {
+		fen     string
+		castles int
+	}{
+		"both sides":      {"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", 2},
+		"in check":        {"r3k2r/8/8/8/4r3/8/8/R3K2R w KQkq - 0 1", 0},
+		"through check":   {"r3k2r/8/8/8/5r2/8/8/R3K2R w KQkq - 0 1", 1},
+		"into check":      {"r3k2r/8/8/8/2r5/8/8/R3K2R w KQkq - 0 1", 1},
+		"b-file attacked": {"r3k2r/8/8/8/1r6/8/8/R3K2R w KQkq - 0 1", 2},
+		"rook attacked":   {"r3k2r/8/8/8/7r/8/8/R3K2R w KQkq - 0 1", 2},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := positionFromFEN(test.fen)
+			var castles int
+			for _, m := range p.LegalMoves(nil) {
+				if m.IsCastle() {
+					castles++
+				}
+			}
+			assert.Equal(t, test.castles, castles)
+		})
+	}
+}
+
+func TestChess960Perft(t *testing.T) {
+	// bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9
+	setup := NewCastlingSetup(
+		CastlingSquares{King: 6, KingsideRook: 7, QueensideRook: 5},
+		CastlingSquares{King: 62, KingsideRook: 63, QueensideRook: 61},
+		true,
+	)
+
+	for depth, nodes := range []int{21, 528, 12189, 326672} {
+		p := positionFromFEN("bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w KQkq - 2 9", WithCastlingSetup(setup))
+		assert.Equal(t, nodes, p.Perft(depth+1), "depth %d", depth+1)
+	}
+}
+
+func TestChess960CastlingMoves(t *testing.T) {
+	// king on b1 with rooks on a1 and h1
+	setup := NewCastlingSetup(
+		CastlingSquares{King: 1, KingsideRook: 7, QueensideRook: 0},
+		CastlingSquares{King: 57, KingsideRook: 63, QueensideRook: 56},
+		true,
+	)
+	p := positionFromFEN("rk5r/8/8/8/8/8/8/RK5R w KQ - 0 1", WithCastlingSetup(setup))
+
+	var castles []string
+	for _, m := range p.LegalMoves(nil) {
+		if m.IsCastle() {
+			castles = append(castles, m.String())
+			assert.Equal(t, m.To, p.CastlingRook(m))
+		}
+	}
+	assert.ElementsMatch(t, []string{"b1h1", "b1a1"}, castles)
+
+	p.MakeMove(Move{From: 1, To: 0, flags: flagCastle})
+	assert.Equal(t, piece.King*piece.White, p.Piece(2))
+	assert.Equal(t, piece.Rook*piece.White, p.Piece(3))
+	assert.Equal(t, piece.Empty, p.Piece(0))
+	assert.Equal(t, piece.Empty, p.Piece(1))
+	assert.Zero(t, p.Castling()&(WhiteKingside|WhiteQueenside))
+}
+
+// legalMovesByCopyMake is the make/check filter the generator used before it
+// knew about pins and checks, kept to cross-check the faster version
+func legalMovesByCopyMake(p *Position) []Move {
+	var legal []Move
+	for _, m := range p.PseudoLegalMoves(nil) {
+		next := *p
+		next.MakeMove(m)
+
+		if next.attackersOf(next.king(p.side), next.side, next.Occupied()) == 0 {
+			legal = append(legal, m)
+		}
+	}
+
+	return legal
+}
+
+func TestLegalMovesMatchCopyMake(t *testing.T) {
+	rng := xorshift(2024)
+
+	for name, test := range perftPositions {
+		t.Run(name, func(t *testing.T) {
+			for game := 0; game < 20; game++ {
+				p := positionFromFEN(test.fen)
+
+				for ply := 0; ply < 80; ply++ {
+					expected := legalMovesByCopyMake(&p)
+					actual := p.LegalMoves(nil)
+					if !assert.ElementsMatch(t, expected, actual, "%s after %d plies", name, ply) || len(actual) == 0 {
+						break
+					}
+
+					p.MakeMove(actual[rng.next()%uint64(len(actual))])
+				}
+			}
+		})
+	}
+}
+
+func TestCrazyhouseMatchesCopyMake(t *testing.T) {
+	rng := xorshift(960)
+	full := piece.Pocket{piece.Pawn: 2, piece.Knight: 1, piece.Bishop: 1, piece.Rook: 1, piece.Queen: 1}
+
+	for name, test := range perftPositions {
+		t.Run(name, func(t *testing.T) {
+			for game := 0; game < 10; game++ {
+				p := positionFromFEN(test.fen, WithPockets(full, full, 0))
+
+				for ply := 0; ply < 80; ply++ {
+					expected := legalMovesByCopyMake(&p)
+					actual := p.LegalMoves(nil)
+					if !assert.ElementsMatch(t, expected, actual, "%s after %d plies", name, ply) || len(actual) == 0 {
+						break
+					}
+
+					p.MakeMove(actual[rng.next()%uint64(len(actual))])
+					assert.Equal(t, p.computeHash(), p.Hash())
+				}
+			}
+		})
+	}
+}
+
+func TestCrazyhouseMakeMove(t *testing.T) {
+	p := positionFromFEN("r3k3/8/8/8/8/8/8/Q3K3 b - - 0 1", WithPockets(piece.Pocket{}, piece.Pocket{}, SquareBB(0)))
+
+	p.MakeMove(Move{From: 56, To: 0, flags: flagCapture})
+	assert.Equal(t, piece.Pocket{piece.Pawn: 1}, p.Pocket(piece.Black))
+	assert.Zero(t, p.Promoted())
+
+	p.MakeMove(Move{From: 4, To: 12})
+	p.MakeMove(Move{From: NoSquare, To: 19, Drop: piece.Pawn})
+	assert.Equal(t, piece.Pawn*piece.Black, p.Piece(19))
+	assert.True(t, p.Pocket(piece.Black).Empty())
+	assert.True(t, p.InCheck())
+	assert.Equal(t, p.computeHash(), p.Hash())
+
+	assert.Equal(t, "P@d3", Move{From: NoSquare, To: 19, Drop: piece.Pawn}.String())
+}
+
+func TestLegalMovesEdgeCases(t *testing.T) {
+	tests := map[string]struct {
+		fen   string
+		moves int
+	}{
+		"en passant exposes king on rank": {"8/8/8/K2pP2r/8/8/8/7k w - d6 0 1", 6},
+		"en passant captures checker":     {"8/8/8/2k5/3Pp3/8/8/4K3 b - d3 0 1", 9},
+		"en passant ignores knight check": {"8/8/8/8/3Pp3/5N2/8/4k1K1 b - d3 0 1", 3},
+		"double check":                    {"4k3/8/8/8/8/3n4/8/r3K3 w - - 0 1", 2},
+		"pinned knight":                   {"4k3/4r3/8/8/8/8/4N3/4K3 w - - 0 1", 4},
+		"pinned rook slides along pin":    {"4k3/4r3/8/8/8/8/4R3/4K3 w - - 0 1", 9},
+		"king cannot retreat along check": {"4k3/8/8/8/8/8/8/r3K3 w - - 0 1", 3},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := positionFromFEN(test.fen)
+			moves := p.LegalMoves(nil)
+			assert.ElementsMatch(t, legalMovesByCopyMake(&p), moves)
+			assert.Len(t, moves, test.moves)
+		})
+	}
+}
+
+func TestCanCaptureEnPassant(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		expected bool
+	}{
+		"capture":                    {"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", true},
+		"no pawn next to it":         {"4k3/8/8/3p4/8/8/4P3/4K3 w - d6 0 1", false},
+		"no target":                  {"4k3/8/8/3pP3/8/8/8/4K3 w - - 0 1", false},
+		"exposes king on rank":       {"8/8/8/K2pP2r/8/8/8/7k w - d6 0 1", false},
+		"pinned on the diagonal":     {"4k3/6b1/8/3pP3/8/8/1K6/8 w - d6 0 1", false},
+		"captures along the pin":     {"4k3/2b5/8/3pP3/8/6K1/8/8 w - d6 0 1", true},
+		"one of two pawns is pinned": {"4k3/6b1/8/2PpP3/8/8/1K6/8 w - d6 0 1", true},
+		"captures the checker":       {"4k3/8/8/3pP3/4K3/8/8/8 w - d6 0 1", true},
+		"ignores knight check":       {"8/8/8/8/3Pp3/5N2/8/4k1K1 b - d3 0 1", false},
+		"doesn't block a check":      {"4k3/8/8/3pP3/8/8/8/q3K3 w - d6 0 1", false},
+		"blocks a check":             {"4k3/4b3/8/2KpP3/8/8/8/8 w - d6 0 1", true},
+		"double check":               {"4k3/8/8/3pP3/8/5n2/8/r3K3 w - d6 0 1", false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := positionFromFEN(test.fen)
+			assert.Equal(t, test.expected, p.CanCaptureEnPassant())
+
+			legal := false
+			for _, m := range p.LegalMoves(nil) {
+				legal = legal || m.flags&flagEnPassant != 0
+			}
+			assert.Equal(t, legal, p.CanCaptureEnPassant())
+		})
+	}
+}
+
+func BenchmarkPerft(b *testing.B) {
+	p := positionFromFEN(board.StartingFEN)
+	for i := 0; i < b.N; i++ {
+		p.Perft(3)
+	}
+}
@@ -0,0 +1,271 @@
+package bitboard
+
+import (
+	"fmt"
+
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+)
+
+const (
+	white = 0
+	black = 1
+)
+
+const (
+	flagCapture uint8 = 1 << iota
+	flagDoublePush
+	flagEnPassant
+	flagCastle
+)
+
+type Move struct {
+	From, To  int
+	Promotion piece.Piece
+	// Drop is the piece dropped in crazyhouse, from no square
+	Drop  piece.Piece
+	flags uint8
+}
+
+func (m Move) IsCapture() bool {
+	return m.flags&flagCapture != 0
+}
+
+func (m Move) IsCastle() bool {
+	return m.flags&flagCastle != 0
+}
+
+func (m Move) IsDrop() bool {
+	return m.Drop != piece.Empty
+}
+
+func (m Move) String() string {
+	if m.IsDrop() {
+		return m.Drop.FEN() + "@" + SquareName(m.To)
+	}
+
+	if m.Promotion != piece.Empty {
+		return SquareName(m.From) + SquareName(m.To) + fmt.Sprintf("%c", "  nbrq"[m.Promotion.Type()])
+	}
+
+	return SquareName(m.From) + SquareName(m.To)
+}
+
+func (m Move) ToMove() move.Move {
+	if m.IsDrop() {
+		return move.NewDropMove(m.Drop, SquareName(m.To))
+	}
+
+	return move.Move{Source: SquareName(m.From), Target: SquareName(m.To), Promotion: m.Promotion}
+}
+
+type Position struct {
+	pieces    [2][7]Bitboard
+	occupied  [2]Bitboard
+	squares   [64]piece.Piece
+	side      int
+	castling  uint8
+	enPassant int
+	hash      uint64
+	// nil for standard chess
+	setup *CastlingSetup
+	// crazyhouse pockets, and the pieces that were pawns and go back to
+	// being pawns when captured
+	crazyhouse bool
+	pockets    [2]piece.Pocket
+	promoted   Bitboard
+}
+
+func colorIndex(c piece.Piece) int {
+	if c.Color() == piece.Black {
+		return black
+	}
+
+	return white
+}
+
+func colorPiece(side int) piece.Piece {
+	if side == black {
+		return piece.Black
+	}
+
+	return piece.White
+}
+
+func NewPosition(squares [64]piece.Piece, activeColor piece.Piece, castling uint8, enPassant int, opts ...func(*Position)) Position {
+	p := Position{
+		side:      colorIndex(activeColor),
+		castling:  castling,
+		enPassant: enPassant,
+	}
+
+	for _, opt := range opts {
+		opt(&p)
+	}
+
+	for sq, pc := range squares {
+		if pc != piece.Empty {
+			p.put(sq, pc)
+		}
+	}
+
+	p.hash ^= ZobristCastling(p.castling) ^ p.EnPassantKey() ^ p.pocketsKey()
+	if p.side == white {
+		p.hash ^= ZobristWhiteToMove()
+	}
+
+	return p
+}
+
+// WithCastlingSetup castles from other squares than the standard ones, as in chess960
+func WithCastlingSetup(setup *CastlingSetup) func(*Position) {
+	return func(p *Position) {
+		if setup != StandardCastling {
+			p.setup = setup
+		}
+	}
+}
+
+func (p Position) CastlingSetup() *CastlingSetup {
+	if p.setup == nil {
+		return StandardCastling
+	}
+
+	return p.setup
+}
+
+// CastlingRook is the square of the rook that castles with m
+func (p Position) CastlingRook(m Move) int {
+	return p.CastlingSetup().castleFor(p.side, m).rook
+}
+
+func (p *Position) put(sq int, pc piece.Piece) {
+	c := colorIndex(pc)
+	p.pieces[c][pc.Type()] |= SquareBB(sq)
+	p.occupied[c] |= SquareBB(sq)
+	p.squares[sq] = pc
+	p.hash ^= ZobristPiece(pc, sq)
+}
+
+func (p *Position) remove(sq int) {
+	pc := p.squares[sq]
+	if pc == piece.Empty {
+		return
+	}
+
+	c := colorIndex(pc)
+	p.pieces[c][pc.Type()] &^= SquareBB(sq)
+	p.occupied[c] &^= SquareBB(sq)
+	p.squares[sq] = piece.Empty
+	p.hash ^= ZobristPiece(pc, sq)
+}
+
+func (p Position) Piece(sq int) piece.Piece {
+	return p.squares[sq]
+}
+
+func (p Position) SideToMove() piece.Piece {
+	return colorPiece(p.side)
+}
+
+func (p Position) Castling() uint8 {
+	return p.castling
+}
+
+func (p Position) EnPassant() int {
+	return p.enPassant
+}
+
+func (p Position) Occupied() Bitboard {
+	return p.occupied[white] | p.occupied[black]
+}
+
+func (p Position) Pieces(color piece.Piece, pieceType piece.Piece) Bitboard {
+	return p.pieces[colorIndex(color)][pieceType.Type()]
+}
+
+func (p Position) king(side int) int {
+	return p.pieces[side][piece.King].LSB()
+}
+
+func (p Position) attackersOf(sq int, side int, occupied Bitboard) Bitboard {
+	them := p.pieces[side]
+
+	return pawnAttacks[side^1][sq]&them[piece.Pawn] |
+		knightAttacks[sq]&them[piece.Knight] |
+		kingAttacks[sq]&them[piece.King] |
+		BishopAttacks(sq, occupied)&(them[piece.Bishop]|them[piece.Queen]) |
+		RookAttacks(sq, occupied)&(them[piece.Rook]|them[piece.Queen])
+}
+
+func (p Position) IsAttacked(sq int, by piece.Piece) bool {
+	return p.attackersOf(sq, colorIndex(by), p.Occupied()) != 0
+}
+
+func (p Position) InCheck() bool {
+	if p.pieces[p.side][piece.King] == 0 {
+		return false
+	}
+
+	return p.attackersOf(p.king(p.side), p.side^1, p.Occupied()) != 0
+}
+
+func (p *Position) MakeMove(m Move) {
+	var pc piece.Piece
+	if !m.IsDrop() {
+		pc = p.squares[m.From]
+	}
+
+	forward := 8
+	if p.side == black {
+		forward = -8
+	}
+
+	p.hash ^= ZobristCastling(p.castling) ^ p.EnPassantKey()
+
+	setup := p.CastlingSetup()
+
+	if p.crazyhouse {
+		p.pocketCapture(m, forward)
+	}
+
+	switch {
+	case m.IsDrop():
+		p.takeFromPocket(p.side, m.Drop)
+		p.put(m.To, m.Drop.Type()*colorPiece(p.side))
+	case m.flags&flagCastle != 0:
+		// the king and rook can land on each other's squares in chess960, so
+		// both leave the board first
+		c := setup.castleFor(p.side, m)
+		rook := p.squares[c.rook]
+		p.remove(m.From)
+		p.remove(c.rook)
+		p.put(c.target, pc)
+		p.put(c.rookTarget, rook)
+	case m.Promotion != piece.Empty:
+		p.remove(m.To)
+		p.remove(m.From)
+		p.put(m.To, m.Promotion.Type()*colorPiece(p.side))
+	default:
+		if m.flags&flagEnPassant != 0 {
+			p.remove(m.To - forward)
+		}
+
+		p.remove(m.To)
+		p.remove(m.From)
+		p.put(m.To, pc)
+	}
+
+	if !m.IsDrop() {
+		p.castling &= setup.mask[m.From] & setup.mask[m.To]
+	}
+
+	if m.flags&flagDoublePush != 0 {
+		p.enPassant = m.From + forward
+	} else {
+		p.enPassant = NoSquare
+	}
+
+	p.side ^= 1
+	p.hash ^= ZobristCastling(p.castling) ^ p.EnPassantKey() ^ ZobristWhiteToMove()
+}
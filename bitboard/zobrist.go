@@ -0,0 +1,133 @@
+package bitboard
+
+import (
+	"github.com/ethansaxenian/chess/piece"
+)
+
+// the keys are polyglot's: 768 piece-square keys, 4 castling keys, 8 en
+// passant file keys and a key for white to move, so a standard position
+// hashes to its polyglot book key
+const (
+	zobristCastlingOffset  = 768
+	zobristEnPassantOffset = 772
+	zobristTurnOffset      = 780
+)
+
+// crazyhouse pockets never hold more than the 16 pawns
+const maxPocket = 16
+
+var (
+	zobristCastling [16]uint64
+	// crazyhouse isn't part of polyglot, so its keys come from a generator
+	zobristPockets [2][piece.King][maxPocket + 1]uint64
+	// nor are three-check's counters, which only the state keeps
+	zobristChecks [2][maxChecks + 1]uint64
+)
+
+const maxChecks = 3
+
+func initZobrist() {
+	for rights := range zobristCastling {
+		for i := 0; i < 4; i++ {
+			if rights&(1<<i) != 0 {
+				zobristCastling[rights] ^= random64[zobristCastlingOffset+i]
+			}
+		}
+	}
+
+	rng := xorshift(1070372)
+	for side := range zobristPockets {
+		for pieceType := piece.Pawn; pieceType < piece.King; pieceType++ {
+			for n := 1; n <= maxPocket; n++ {
+				zobristPockets[side][pieceType][n] = rng.next()
+			}
+		}
+	}
+
+	for side := range zobristChecks {
+		for n := 1; n <= maxChecks; n++ {
+			zobristChecks[side][n] = rng.next()
+		}
+	}
+}
+
+// ZobristPiece is the key for a piece on a square. polyglot orders the kinds
+// black pawn, white pawn, black knight, ... white king
+func ZobristPiece(p piece.Piece, sq int) uint64 {
+	kind := 2 * (int(p.Type()) - 1)
+	if p.Color() == piece.White {
+		kind++
+	}
+
+	return random64[64*kind+sq]
+}
+
+func ZobristCastling(rights uint8) uint64 {
+	return zobristCastling[rights&0xf]
+}
+
+func ZobristEnPassant(file int) uint64 {
+	return random64[zobristEnPassantOffset+file]
+}
+
+func ZobristWhiteToMove() uint64 {
+	return random64[zobristTurnOffset]
+}
+
+// ZobristPocket is the key for holding an nth piece of a kind in a crazyhouse
+// pocket, so a pocket's key is that of every piece in it
+func ZobristPocket(p piece.Piece, n int) uint64 {
+	return zobristPockets[colorIndex(p)][p.Type()][min(n, maxPocket)]
+}
+
+// ZobristChecks is the key for color having given n checks in three-check
+func ZobristChecks(color piece.Piece, n int) uint64 {
+	return zobristChecks[colorIndex(color)][min(n, maxChecks)]
+}
+
+func pocketKey(color piece.Piece, pocket piece.Pocket) uint64 {
+	var key uint64
+	for pieceType := piece.Pawn; pieceType < piece.King; pieceType++ {
+		for n := 1; n <= pocket[pieceType]; n++ {
+			key ^= ZobristPocket(pieceType*color, n)
+		}
+	}
+
+	return key
+}
+
+func (p *Position) pocketsKey() uint64 {
+	return pocketKey(piece.White, p.pockets[white]) ^ pocketKey(piece.Black, p.pockets[black])
+}
+
+// EnPassantKey only hashes the en passant file when a pawn of the side to move
+// stands ready to capture, as polyglot does
+func (p *Position) EnPassantKey() uint64 {
+	if p.enPassant == NoSquare || pawnAttacks[p.side^1][p.enPassant]&p.pieces[p.side][piece.Pawn] == 0 {
+		return 0
+	}
+
+	return ZobristEnPassant(p.enPassant % 8)
+}
+
+func (p Position) Hash() uint64 {
+	return p.hash
+}
+
+// computeHash builds the key from scratch, to check the incremental one
+func (p *Position) computeHash() uint64 {
+	var hash uint64
+
+	for sq, pc := range p.squares {
+		if pc != piece.Empty {
+			hash ^= ZobristPiece(pc, sq)
+		}
+	}
+
+	hash ^= ZobristCastling(p.castling) ^ p.EnPassantKey() ^ p.pocketsKey()
+	if p.side == white {
+		hash ^= ZobristWhiteToMove()
+	}
+
+	return hash
+}
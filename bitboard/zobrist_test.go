@@ -0,0 +1,103 @@
+package bitboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashIsIncremental(t *testing.T) {
+	rng := xorshift(99)
+
+	for name, test := range perftPositions {
+		t.Run(name, func(t *testing.T) {
+			for game := 0; game < 20; game++ {
+				p := positionFromFEN(test.fen)
+				assert.Equal(t, p.computeHash(), p.Hash())
+
+				for ply := 0; ply < 80; ply++ {
+					moves := p.LegalMoves(nil)
+					if len(moves) == 0 {
+						break
+					}
+
+					p.MakeMove(moves[rng.next()%uint64(len(moves))])
+					if !assert.Equal(t, p.computeHash(), p.Hash(), "%s after %d plies", name, ply) {
+						return
+					}
+				}
+			}
+		})
+	}
+}
+
+func playUCI(p *Position, moves ...string) {
+	for _, uci := range moves {
+		for _, m := range p.LegalMoves(nil) {
+			if m.String() == uci {
+				p.MakeMove(m)
+				break
+			}
+		}
+	}
+}
+
+func TestHashTranspositions(t *testing.T) {
+	start := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+	a := positionFromFEN(start)
+	playUCI(&a, "g1f3", "g8f6", "b1c3")
+
+	b := positionFromFEN(start)
+	playUCI(&b, "b1c3", "g8f6", "g1f3")
+
+	assert.Equal(t, a.Hash(), b.Hash())
+
+	// the same position reached by a different route, but not with the other side to move
+	c := positionFromFEN(start)
+	playUCI(&c, "g1f3", "g8f6", "f3g1")
+	assert.Equal(t, positionFromFEN("rnbqkb1r/pppppppp/5n2/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 1 2").Hash(), c.Hash())
+	assert.NotEqual(t, positionFromFEN("rnbqkb1r/pppppppp/5n2/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 1 2").Hash(), c.Hash())
+
+	// losing a castling right changes the key even if the pieces return
+	e := positionFromFEN(start)
+	playUCI(&e, "g1f3", "g8f6", "h1g1", "f6g8", "g1h1", "g8f6", "f3g1", "f6g8")
+	assert.NotEqual(t, positionFromFEN(start).Hash(), e.Hash())
+}
+
+func TestHashEnPassant(t *testing.T) {
+	// no black pawn can capture on e3, so the file isn't hashed
+	withTarget := positionFromFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	withoutTarget := positionFromFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
+	assert.Equal(t, withoutTarget.Hash(), withTarget.Hash())
+
+	withTarget = positionFromFEN("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	withoutTarget = positionFromFEN("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
+	assert.Equal(t, withoutTarget.Hash()^ZobristEnPassant(4), withTarget.Hash())
+}
+
+// the examples from the polyglot book format's description
+func TestHashPolyglotKeys(t *testing.T) {
+	tests := map[string]struct {
+		moves    []string
+		expected uint64
+	}{
+		"starting position":       {nil, 0x463b96181691fc9c},
+		"e4":                      {[]string{"e2e4"}, 0x823c9b50fd114196},
+		"e4 d5":                   {[]string{"e2e4", "d7d5"}, 0x0756b94461c50fb0},
+		"e4 d5 e5":                {[]string{"e2e4", "d7d5", "e4e5"}, 0x662fafb965db29d4},
+		"e4 d5 e5 f5":             {[]string{"e2e4", "d7d5", "e4e5", "f7f5"}, 0x22a48b5a8e47ff78},
+		"e4 d5 e5 f5 Ke2":         {[]string{"e2e4", "d7d5", "e4e5", "f7f5", "e1e2"}, 0x652a607ca3f242c1},
+		"e4 d5 e5 f5 Ke2 Kf7":     {[]string{"e2e4", "d7d5", "e4e5", "f7f5", "e1e2", "e8f7"}, 0x00fdd303c946bdd9},
+		"a4 b5 h4 b4 c4":          {[]string{"a2a4", "b7b5", "h2h4", "b5b4", "c2c4"}, 0x3c8123ea7b067637},
+		"a4 b5 h4 b4 c4 bxc3 Ra3": {[]string{"a2a4", "b7b5", "h2h4", "b5b4", "c2c4", "b4c3", "a1a3"}, 0x5c3f9b829b279560},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := positionFromFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+			playUCI(&p, test.moves...)
+			assert.Equal(t, test.expected, p.Hash())
+		})
+	}
+}
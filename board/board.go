@@ -3,6 +3,7 @@ package board
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"unicode"
 
 	"github.com/ethansaxenian/chess/assert"
@@ -19,6 +20,44 @@ const (
 
 const StartingFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
 
+type SquareError struct {
+	Square string
+}
+
+func (e *SquareError) Error() string {
+	return fmt.Sprintf("invalid square: %q", e.Square)
+}
+
+// PlacementError describes a problem in the piece placement field of a FEN.
+// Rank is 1-8, or 0 when the problem isn't in a single rank, and Square is set
+// when it is about a single square
+type PlacementError struct {
+	Placement string
+	Rank      int
+	Square    string
+	Reason    string
+}
+
+func (e *PlacementError) Error() string {
+	switch {
+	case e.Square != "":
+		return fmt.Sprintf("invalid piece placement %q: %s: %s", e.Placement, e.Square, e.Reason)
+	case e.Rank != 0:
+		return fmt.Sprintf("invalid piece placement %q: rank %d: %s", e.Placement, e.Rank, e.Reason)
+	default:
+		return fmt.Sprintf("invalid piece placement %q: %s", e.Placement, e.Reason)
+	}
+}
+
+// ParseSquare returns the board index of a square like "e4"
+func ParseSquare(square string) (int, error) {
+	if len(square) != 2 || square[0] < 'a' || square[0] > 'h' || square[1] < '1' || square[1] > '8' {
+		return 0, &SquareError{square}
+	}
+
+	return int(square[1]-'1')*boardLength + int(square[0]-'a'), nil
+}
+
 func SquareToCoords(square string) (int, int) {
 	f := int(square[0])
 	r, err := strconv.Atoi(string(square[1]))
@@ -64,20 +103,60 @@ func indexToSquare(index int) string {
 type Chessboard [64]piece.Piece
 
 func LoadFEN(piecePlacement string) Chessboard {
+	board, err := ParsePiecePlacement(piecePlacement)
+	assert.ErrIsNil(err, fmt.Sprint(err))
+	return board
+}
 
-	file := 0
-	rank := 7
+func ParsePiecePlacement(piecePlacement string) (Chessboard, error) {
+	board, errs := parsePiecePlacement(piecePlacement)
+	if len(errs) > 0 {
+		return board, errs[0]
+	}
 
+	return board, nil
+}
+
+// ValidatePiecePlacement returns every problem in a piece placement, rather
+// than just the first
+func ValidatePiecePlacement(piecePlacement string) []*PlacementError {
+	_, errs := parsePiecePlacement(piecePlacement)
+	return errs
+}
+
+func parsePiecePlacement(piecePlacement string) (Chessboard, []*PlacementError) {
 	var board Chessboard
+	var errs []*PlacementError
+
+	ranks := strings.Split(piecePlacement, "/")
+	if len(ranks) != boardLength {
+		errs = append(errs, &PlacementError{Placement: piecePlacement, Reason: fmt.Sprintf("expected %d ranks, got %d", boardLength, len(ranks))})
+	}
+
+	for i, rankPlacement := range ranks {
+		rank := boardLength - 1 - i
+		if rank < 0 {
+			break
+		}
+
+		file := 0
 
-	for _, char := range piecePlacement {
-		if char == '/' {
-			rank--
-			file = 0
-		} else if unicode.IsDigit(char) {
-			file += int(char - '0')
-		} else {
-			p := piece.CharToPiece[unicode.ToLower(char)]
+		for _, char := range rankPlacement {
+			if char >= '1' && char <= '8' {
+				file += int(char - '0')
+				continue
+			}
+
+			p, ok := piece.CharToPiece[unicode.ToLower(char)]
+			if !ok || p == piece.Empty {
+				err := &PlacementError{Placement: piecePlacement, Rank: rank + 1, Reason: fmt.Sprintf("unknown piece %q", char)}
+				if file < boardLength {
+					err.Square = indexToSquare(rank*boardLength + file)
+				}
+				errs = append(errs, err)
+				file++
+				continue
+			}
 
 			var color piece.Piece
 			if unicode.IsUpper(char) {
@@ -86,12 +165,19 @@ func LoadFEN(piecePlacement string) Chessboard {
 				color = piece.Black
 			}
 
-			board[rank*boardLength+file] = p * color
+			// overlong ranks are reported below
+			if file < boardLength {
+				board[rank*boardLength+file] = p * color
+			}
 			file++
 		}
+
+		if file != boardLength {
+			errs = append(errs, &PlacementError{Placement: piecePlacement, Rank: rank + 1, Reason: fmt.Sprintf("expected %d squares, got %d", boardLength, file)})
+		}
 	}
 
-	return board
+	return board, errs
 }
 
 func (b Chessboard) FEN() string {
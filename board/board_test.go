@@ -42,3 +42,60 @@ func TestBoardToFen(t *testing.T) {
 	newFen := "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR"
 	assert.Equal(t, newFen, b.FEN())
 }
+
+func TestParseSquare(t *testing.T) {
+	index, err := ParseSquare("e4")
+	assert.NoError(t, err)
+	assert.Equal(t, 28, index)
+
+	for _, invalid := range []string{"", "e", "e44", "i4", "e0", "E4"} {
+		_, err := ParseSquare(invalid)
+
+		var squareErr *SquareError
+		if assert.ErrorAs(t, err, &squareErr, invalid) {
+			assert.Equal(t, invalid, squareErr.Square)
+		}
+	}
+}
+
+func TestParsePiecePlacement(t *testing.T) {
+	b, err := ParsePiecePlacement(strings.Fields(StartingFEN)[0])
+	assert.NoError(t, err)
+	assert.Equal(t, LoadFEN(strings.Fields(StartingFEN)[0]), b)
+
+	tests := map[string]struct {
+		placement string
+		rank      int
+	}{
+		"too few ranks":     {"8/8/8/8/8/8/8", 0},
+		"too many ranks":    {"8/8/8/8/8/8/8/8/8", 0},
+		"nine squares":      {"8/8/8/8/8/8/8/ppppppppp", 1},
+		"seven squares":     {"rnbqkbn/8/8/8/8/8/8/8", 8},
+		"too many empties":  {"8/8/44p/8/8/8/8/8", 6},
+		"unknown piece":     {"8/8/8/8/3x4/8/8/8", 4},
+		"empty placeholder": {"8/8/8/8/8/8/8/_7", 1},
+		"zero":              {"8/8/8/8/8/8/8/08", 1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParsePiecePlacement(test.placement)
+
+			var placementErr *PlacementError
+			if assert.ErrorAs(t, err, &placementErr) {
+				assert.Equal(t, test.rank, placementErr.Rank)
+			}
+		})
+	}
+}
+
+func TestValidatePiecePlacement(t *testing.T) {
+	assert.Empty(t, ValidatePiecePlacement(strings.Fields(StartingFEN)[0]))
+
+	errs := ValidatePiecePlacement("rnbqkbnrr/ppxppppp/8/8/8/8/PPPPPPPP/RNBQKBN")
+	if assert.Len(t, errs, 3) {
+		assert.Equal(t, 8, errs[0].Rank)
+		assert.Equal(t, "c7", errs[1].Square)
+		assert.Equal(t, 1, errs[2].Rank)
+	}
+}
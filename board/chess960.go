@@ -0,0 +1,69 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Chess960Positions is how many chess960 start positions there are
+const Chess960Positions = 960
+
+// StandardChess960Position is the index of the usual start position
+const StandardChess960Position = 518
+
+// knight placements on the five squares left once the bishops and queen are placed
+var chess960Knights = [10][2]int{{0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4}}
+
+// Chess960BackRank is white's back rank in start position n, numbered as
+// Scharnagl does: the bishops, then the queen, then the knights, and the rooks
+// and king on the squares left over
+func Chess960BackRank(n int) (string, error) {
+	if n < 0 || n >= Chess960Positions {
+		return "", fmt.Errorf("invalid chess960 position %d: must be 0 to %d", n, Chess960Positions-1)
+	}
+
+	var rank [8]byte
+
+	// the nth empty square
+	place := func(nth int, p byte) {
+		for i := range rank {
+			if rank[i] != 0 {
+				continue
+			}
+			if nth == 0 {
+				rank[i] = p
+				return
+			}
+			nth--
+		}
+	}
+
+	rank[n%4*2+1] = 'B'
+	n /= 4
+	rank[n%4*2] = 'B'
+	n /= 4
+	place(n%6, 'Q')
+	n /= 6
+
+	knights := chess960Knights[n]
+	// the second knight's index counts the first one's square as still empty
+	place(knights[1], 'N')
+	place(knights[0], 'N')
+
+	place(0, 'R')
+	place(0, 'K')
+	place(0, 'R')
+
+	return string(rank[:]), nil
+}
+
+// Chess960FEN is the FEN of start position n. the castling rights are X-FEN,
+// where KQkq is enough since each side has one rook on either side of its king
+func Chess960FEN(n int) (string, error) {
+	backRank, err := Chess960BackRank(n)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/pppppppp/8/8/8/8/PPPPPPPP/%s w KQkq - 0 1", strings.ToLower(backRank), backRank), nil
+}
@@ -0,0 +1,54 @@
+package board
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChess960BackRank(t *testing.T) {
+	tests := map[int]string{
+		0:                        "BBQNNRKR",
+		StandardChess960Position: "RNBQKBNR",
+		959:                      "RKRNNQBB",
+	}
+
+	for n, want := range tests {
+		rank, err := Chess960BackRank(n)
+		assert.NoError(t, err)
+		assert.Equal(t, want, rank, n)
+	}
+
+	_, err := Chess960BackRank(960)
+	assert.Error(t, err)
+	_, err = Chess960BackRank(-1)
+	assert.Error(t, err)
+}
+
+func TestChess960BackRanksAreValid(t *testing.T) {
+	seen := map[string]bool{}
+
+	for n := 0; n < Chess960Positions; n++ {
+		rank, err := Chess960BackRank(n)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 2, strings.Count(rank, "B"))
+		assert.NotEqual(t, strings.Index(rank, "B")%2, strings.LastIndex(rank, "B")%2, rank)
+		assert.Equal(t, 2, strings.Count(rank, "N"))
+		assert.Equal(t, 1, strings.Count(rank, "Q"))
+
+		king := strings.Index(rank, "K")
+		assert.True(t, strings.Index(rank, "R") < king && king < strings.LastIndex(rank, "R"), rank)
+
+		seen[rank] = true
+	}
+
+	assert.Len(t, seen, Chess960Positions)
+}
+
+func TestChess960FEN(t *testing.T) {
+	fen, err := Chess960FEN(StandardChess960Position)
+	assert.NoError(t, err)
+	assert.Equal(t, StartingFEN, fen)
+}
@@ -0,0 +1,247 @@
+package clock
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethansaxenian/chess/piece"
+)
+
+// reading is taken every time the clock is pressed, so a move can be taken
+// back and the game's times written out
+type reading struct {
+	color     piece.Piece
+	moves     int
+	period    int
+	remaining time.Duration
+	// increments and new periods added by the press
+	bonus time.Duration
+}
+
+// Clock is a chess clock for both players. it is safe to read from another
+// goroutine while the game goes on
+type Clock struct {
+	mu      sync.Mutex
+	control TimeControl
+	now     func() time.Time
+
+	remaining map[piece.Piece]time.Duration
+	// moves made and periods used up by each side
+	moves  map[piece.Piece]int
+	period map[piece.Piece]int
+
+	turn    piece.Piece
+	started time.Time
+	running bool
+	flagged piece.Piece
+
+	readings []reading
+}
+
+func New(control TimeControl, opts ...func(*Clock)) *Clock {
+	start := control.period(0).Time
+
+	c := &Clock{
+		control:   control,
+		now:       time.Now,
+		remaining: map[piece.Piece]time.Duration{piece.White: start, piece.Black: start},
+		moves:     map[piece.Piece]int{},
+		period:    map[piece.Piece]int{},
+		turn:      piece.White,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithTimeSource replaces time.Now, mostly for tests
+func WithTimeSource(now func() time.Time) func(*Clock) {
+	return func(c *Clock) {
+		c.now = now
+	}
+}
+
+func (c *Clock) TimeControl() TimeControl {
+	return c.control
+}
+
+// Start runs color's clock, unless the clock is already running
+func (c *Clock) Start(color piece.Piece) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running || c.flagged != piece.Empty {
+		return
+	}
+
+	c.turn = color
+	c.started = c.now()
+	c.running = true
+}
+
+func (c *Clock) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remaining[c.turn] = c.remainingLocked(c.turn)
+	c.running = false
+}
+
+// Press ends the running side's move: its time is used up, then its
+// increment and the next period's time are added, and the other side's clock
+// starts. a side whose time ran out before the press loses on time
+func (c *Clock) Press() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running {
+		return
+	}
+
+	color := c.turn
+	p := c.control.period(c.period[color])
+	used := c.now().Sub(c.started)
+
+	r := reading{color: color, moves: c.moves[color], period: c.period[color]}
+
+	if p.Mode == Delay {
+		used = max(used-p.Increment, 0)
+	}
+
+	c.remaining[color] -= used
+	if c.remaining[color] <= 0 {
+		c.remaining[color] = 0
+		c.flagged = color
+		c.running = false
+		return
+	}
+
+	afterMove := c.remaining[color]
+
+	switch p.Mode {
+	case Fischer:
+		c.remaining[color] += p.Increment
+	case Bronstein:
+		c.remaining[color] += min(used, p.Increment)
+	}
+
+	c.moves[color]++
+	if p.Moves > 0 && c.moves[color] == p.Moves {
+		c.moves[color] = 0
+		c.period[color]++
+		c.remaining[color] += c.control.period(c.period[color]).Time
+	}
+
+	r.remaining = c.remaining[color]
+	r.bonus = c.remaining[color] - afterMove
+	c.readings = append(c.readings, r)
+
+	c.turn = color * -1
+	c.started = c.now()
+}
+
+// Undo gives the last move back to the side that made it and takes back what
+// the move earned it, but time already used stays used. a loss on time can't
+// be undone
+func (c *Clock) Undo() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.readings) == 0 || c.flagged != piece.Empty {
+		return
+	}
+
+	r := c.readings[len(c.readings)-1]
+	c.readings = c.readings[:len(c.readings)-1]
+
+	if c.running {
+		c.remaining[c.turn] = c.remainingLocked(c.turn)
+	}
+
+	c.remaining[r.color] -= r.bonus
+	c.moves[r.color] = r.moves
+	c.period[r.color] = r.period
+	c.turn = r.color
+	c.started = c.now()
+}
+
+// Remaining is how much time color has left, counting the move in progress
+func (c *Clock) Remaining(color piece.Piece) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.remainingLocked(color)
+}
+
+func (c *Clock) remainingLocked(color piece.Piece) time.Duration {
+	if !c.running || color != c.turn {
+		return c.remaining[color]
+	}
+
+	used := c.now().Sub(c.started)
+	if p := c.control.period(c.period[color]); p.Mode == Delay {
+		used = max(used-p.Increment, 0)
+	}
+
+	return max(c.remaining[color]-used, 0)
+}
+
+// Flagged reports the side whose time has run out, if any
+func (c *Clock) Flagged() (piece.Piece, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.flagged != piece.Empty {
+		return c.flagged, true
+	}
+
+	if c.running && c.remainingLocked(c.turn) <= 0 {
+		return c.turn, true
+	}
+
+	return piece.Empty, false
+}
+
+// Deadline is when the running side's flag falls
+func (c *Clock) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running {
+		return time.Time{}, false
+	}
+
+	deadline := c.started.Add(c.remaining[c.turn])
+	if p := c.control.period(c.period[c.turn]); p.Mode == Delay {
+		deadline = deadline.Add(p.Increment)
+	}
+
+	return deadline, true
+}
+
+// Readings are the mover's remaining time after each move, in order
+func (c *Clock) Readings() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	readings := make([]time.Duration, 0, len(c.readings))
+	for _, r := range c.readings {
+		readings = append(readings, r.remaining)
+	}
+
+	return readings
+}
+
+// Format shows a duration the way clocks do, as h:mm:ss
+func Format(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	h := d / time.Hour
+	m := (d % time.Hour) / time.Minute
+	s := (d % time.Minute) / time.Second
+
+	return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+}
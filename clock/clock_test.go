@@ -0,0 +1,193 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTime struct {
+	now time.Time
+}
+
+func (f *fakeTime) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeTime) advance(d time.Duration) {
+	f.now = f.now.Add(d)
+}
+
+func newTestClock(t *testing.T, tc string) (*Clock, *fakeTime) {
+	control, err := ParseTimeControl(tc)
+	assert.NoError(t, err)
+
+	ft := &fakeTime{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	return New(control, WithTimeSource(ft.Now)), ft
+}
+
+func TestIncrementModes(t *testing.T) {
+	tests := map[string]struct {
+		tc       string
+		used     time.Duration
+		expected time.Duration
+	}{
+		"sudden death": {
+			tc:       "5",
+			used:     10 * time.Second,
+			expected: 4*time.Minute + 50*time.Second,
+		},
+		"fischer": {
+			tc:       "5+3",
+			used:     10 * time.Second,
+			expected: 4*time.Minute + 53*time.Second,
+		},
+		"bronstein gives back the time used": {
+			tc:       "5b3",
+			used:     2 * time.Second,
+			expected: 5 * time.Minute,
+		},
+		"bronstein gives back at most the delay": {
+			tc:       "5b3",
+			used:     10 * time.Second,
+			expected: 4*time.Minute + 53*time.Second,
+		},
+		"delay within the delay": {
+			tc:       "5d3",
+			used:     2 * time.Second,
+			expected: 5 * time.Minute,
+		},
+		"delay beyond the delay": {
+			tc:       "5d3",
+			used:     10 * time.Second,
+			expected: 4*time.Minute + 53*time.Second,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, ft := newTestClock(t, test.tc)
+			c.Start(piece.White)
+			ft.advance(test.used)
+			c.Press()
+
+			assert.Equal(t, test.expected, c.Remaining(piece.White))
+			assert.Equal(t, []time.Duration{test.expected}, c.Readings())
+		})
+	}
+}
+
+func TestRemainingWhileRunning(t *testing.T) {
+	c, ft := newTestClock(t, "1d5")
+	c.Start(piece.White)
+
+	ft.advance(3 * time.Second)
+	assert.Equal(t, time.Minute, c.Remaining(piece.White))
+
+	ft.advance(7 * time.Second)
+	assert.Equal(t, 55*time.Second, c.Remaining(piece.White))
+	assert.Equal(t, time.Minute, c.Remaining(piece.Black))
+
+	deadline, ok := c.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, ft.now.Add(55*time.Second), deadline)
+}
+
+func TestFlagFall(t *testing.T) {
+	c, ft := newTestClock(t, "1+2")
+	c.Start(piece.White)
+	ft.advance(30 * time.Second)
+	c.Press()
+
+	ft.advance(59 * time.Second)
+	_, flagged := c.Flagged()
+	assert.False(t, flagged)
+
+	ft.advance(time.Second)
+	color, flagged := c.Flagged()
+	assert.True(t, flagged)
+	assert.Equal(t, piece.Black, color)
+
+	// moving too late doesn't save the flag
+	ft.advance(time.Second)
+	c.Press()
+	color, flagged = c.Flagged()
+	assert.True(t, flagged)
+	assert.Equal(t, piece.Black, color)
+	assert.Equal(t, time.Duration(0), c.Remaining(piece.Black))
+}
+
+func TestPeriods(t *testing.T) {
+	c, ft := newTestClock(t, "2/10+30,5")
+	c.Start(piece.White)
+
+	for range 2 {
+		ft.advance(time.Minute)
+		c.Press()
+		ft.advance(time.Minute)
+		c.Press()
+	}
+
+	// 10 minutes, two moves of a minute each with 30 seconds added, then the
+	// second period
+	assert.Equal(t, 14*time.Minute, c.Remaining(piece.White))
+	assert.Equal(t, 14*time.Minute, c.Remaining(piece.Black))
+
+	ft.advance(time.Minute)
+	c.Press()
+	assert.Equal(t, 13*time.Minute, c.Remaining(piece.White))
+}
+
+func TestRepeatingPeriod(t *testing.T) {
+	c, ft := newTestClock(t, "1/1")
+	c.Start(piece.White)
+
+	for range 3 {
+		ft.advance(30 * time.Second)
+		c.Press()
+		c.Press()
+	}
+
+	assert.Equal(t, 2*time.Minute+30*time.Second, c.Remaining(piece.White))
+	assert.Equal(t, 4*time.Minute, c.Remaining(piece.Black))
+}
+
+func TestUndo(t *testing.T) {
+	c, ft := newTestClock(t, "2/10,5")
+	c.Start(piece.White)
+	ft.advance(time.Minute)
+	c.Press()
+	ft.advance(time.Minute)
+	c.Press()
+	ft.advance(time.Minute)
+	c.Press()
+
+	c.Undo()
+	assert.Len(t, c.Readings(), 2)
+
+	// white moves again and only then reaches the second period
+	ft.advance(time.Minute)
+	c.Press()
+	assert.Equal(t, 12*time.Minute, c.Remaining(piece.White))
+}
+
+func TestStop(t *testing.T) {
+	c, ft := newTestClock(t, "1")
+	c.Start(piece.White)
+	ft.advance(10 * time.Second)
+	c.Stop()
+
+	ft.advance(time.Hour)
+	assert.Equal(t, 50*time.Second, c.Remaining(piece.White))
+	_, flagged := c.Flagged()
+	assert.False(t, flagged)
+	_, ok := c.Deadline()
+	assert.False(t, ok)
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "0:04:58", Format(4*time.Minute+58*time.Second+900*time.Millisecond))
+	assert.Equal(t, "1:30:00", Format(90*time.Minute))
+}
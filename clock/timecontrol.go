@@ -0,0 +1,158 @@
+package clock
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IncrementMode says how a period's Increment is applied after each move
+type IncrementMode int
+
+const (
+	// Fischer adds the increment after every move
+	Fischer IncrementMode = iota
+	// Bronstein gives back the time used on a move, up to the increment
+	Bronstein
+	// Delay waits for the increment before the clock starts running
+	Delay
+)
+
+var incrementSymbols = map[IncrementMode]string{Fischer: "+", Bronstein: "b", Delay: "d"}
+
+// Period is one stage of a time control. Moves is how many moves it lasts,
+// or 0 for the rest of the game
+type Period struct {
+	Moves     int
+	Time      time.Duration
+	Increment time.Duration
+	Mode      IncrementMode
+}
+
+// TimeControl is a list of periods. once the last one is used up it starts
+// again, so 40/90 means 90 minutes for every 40 moves
+type TimeControl struct {
+	Periods []Period
+}
+
+// SuddenDeath is a single period with an optional Fischer increment
+func SuddenDeath(t, increment time.Duration) TimeControl {
+	return TimeControl{[]Period{{Time: t, Increment: increment}}}
+}
+
+// ParseTimeControl reads periods separated by commas. each one is
+// [moves/]minutes[+seconds], where + is a Fischer increment and can also be
+// b for a Bronstein delay or d for a simple delay. for example "5+3",
+// "15d5" or "40/90+30,30+30"
+func ParseTimeControl(tc string) (TimeControl, error) {
+	var control TimeControl
+
+	for _, field := range strings.Split(tc, ",") {
+		p, err := parsePeriod(strings.TrimSpace(field))
+		if err != nil {
+			return TimeControl{}, fmt.Errorf("invalid time control %q: %w", tc, err)
+		}
+
+		control.Periods = append(control.Periods, p)
+	}
+
+	return control, nil
+}
+
+func parsePeriod(s string) (Period, error) {
+	var p Period
+
+	if moves, rest, ok := strings.Cut(s, "/"); ok {
+		n, err := strconv.Atoi(moves)
+		if err != nil || n < 1 {
+			return Period{}, fmt.Errorf("invalid number of moves %q", moves)
+		}
+		p.Moves = n
+		s = rest
+	}
+
+	minutes := s
+	if i := strings.IndexAny(s, "+bd"); i != -1 {
+		minutes = s[:i]
+
+		for mode, symbol := range incrementSymbols {
+			if s[i:i+1] == symbol {
+				p.Mode = mode
+			}
+		}
+
+		seconds, err := strconv.ParseFloat(s[i+1:], 64)
+		if err != nil || seconds < 0 {
+			return Period{}, fmt.Errorf("invalid increment %q", s[i+1:])
+		}
+		p.Increment = time.Duration(seconds * float64(time.Second))
+	}
+
+	m, err := strconv.ParseFloat(minutes, 64)
+	if err != nil || m <= 0 {
+		return Period{}, fmt.Errorf("invalid number of minutes %q", minutes)
+	}
+	p.Time = time.Duration(m * float64(time.Minute))
+
+	if p.Time <= 0 {
+		return Period{}, errors.New("no time on the clock")
+	}
+
+	return p, nil
+}
+
+// period is the one in effect after i periods have been used up
+func (tc TimeControl) period(i int) Period {
+	if i < len(tc.Periods) {
+		return tc.Periods[i]
+	}
+
+	return tc.Periods[len(tc.Periods)-1]
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// String is the format ParseTimeControl reads
+func (tc TimeControl) String() string {
+	periods := make([]string, 0, len(tc.Periods))
+	for _, p := range tc.Periods {
+		var s string
+		if p.Moves > 0 {
+			s = fmt.Sprintf("%d/", p.Moves)
+		}
+
+		s += formatNumber(p.Time.Minutes())
+		if p.Increment > 0 {
+			s += incrementSymbols[p.Mode] + formatNumber(p.Increment.Seconds())
+		}
+
+		periods = append(periods, s)
+	}
+
+	return strings.Join(periods, ",")
+}
+
+// PGN is the value of the TimeControl tag. the standard has no notation for
+// delays, so those are left out
+func (tc TimeControl) PGN() string {
+	periods := make([]string, 0, len(tc.Periods))
+	for _, p := range tc.Periods {
+		var s string
+		if p.Moves > 0 {
+			s = fmt.Sprintf("%d/", p.Moves)
+		}
+
+		s += formatNumber(p.Time.Seconds())
+		if p.Increment > 0 && p.Mode == Fischer {
+			s += "+" + formatNumber(p.Increment.Seconds())
+		}
+
+		periods = append(periods, s)
+	}
+
+	return strings.Join(periods, ":")
+}
@@ -0,0 +1,64 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimeControl(t *testing.T) {
+	tests := map[string]struct {
+		tc       string
+		expected TimeControl
+		pgn      string
+	}{
+		"sudden death": {
+			tc:       "5",
+			expected: SuddenDeath(5*time.Minute, 0),
+			pgn:      "300",
+		},
+		"fischer": {
+			tc:       "5+3",
+			expected: SuddenDeath(5*time.Minute, 3*time.Second),
+			pgn:      "300+3",
+		},
+		"bronstein": {
+			tc:       "15b5",
+			expected: TimeControl{[]Period{{Time: 15 * time.Minute, Increment: 5 * time.Second, Mode: Bronstein}}},
+			pgn:      "900",
+		},
+		"delay": {
+			tc:       "0.5d2",
+			expected: TimeControl{[]Period{{Time: 30 * time.Second, Increment: 2 * time.Second, Mode: Delay}}},
+			pgn:      "30",
+		},
+		"classical": {
+			tc: "40/90+30,30+30",
+			expected: TimeControl{[]Period{
+				{Moves: 40, Time: 90 * time.Minute, Increment: 30 * time.Second},
+				{Time: 30 * time.Minute, Increment: 30 * time.Second},
+			}},
+			pgn: "40/5400+30:1800+30",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tc, err := ParseTimeControl(test.tc)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, tc)
+			assert.Equal(t, test.tc, tc.String())
+			assert.Equal(t, test.pgn, tc.PGN())
+		})
+	}
+}
+
+func TestParseTimeControlErrors(t *testing.T) {
+	for _, tc := range []string{"", "0", "-5", "5+", "5+x", "x/5", "0/5", "5,", "5+3+3"} {
+		t.Run(tc, func(t *testing.T) {
+			_, err := ParseTimeControl(tc)
+			assert.Error(t, err)
+		})
+	}
+}
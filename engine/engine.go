@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/player"
+	"github.com/ethansaxenian/chess/state"
+)
+
+const (
+	defaultTimeLimit = time.Second
+	defaultTableSize = 1 << 18
+	maxDepth         = 64
+	// how many more moves a timed game is assumed to last
+	movesToGo = 30
+)
+
+// Engine is a bot that searches with iterative-deepening alpha-beta. it stops
+// at whichever of its depth or time limit comes first
+type Engine struct {
+	evaluator Evaluator
+	depth     int
+	timeLimit time.Duration
+	tt        *transpositionTable
+	// limits from the GUI, which replace depth and timeLimit when set
+	limits player.SearchLimits
+
+	position bitboard.Position
+	// hashes of the game so far, for spotting repetitions
+	history []uint64
+	// the moves allowed at the root, when the game's variant has rules the
+	// search doesn't know. nil for any legal move
+	rootMoves []move.Move
+}
+
+func New(opts ...func(*Engine)) *Engine {
+	e := &Engine{
+		evaluator: PieceSquareEvaluator{},
+		depth:     maxDepth,
+		timeLimit: defaultTimeLimit,
+		tt:        newTranspositionTable(defaultTableSize),
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// WithDepth searches exactly depth plies, however long that takes
+func WithDepth(depth int) func(*Engine) {
+	return func(e *Engine) {
+		e.depth = min(max(depth, 1), maxDepth)
+		e.timeLimit = 0
+	}
+}
+
+// WithTimeLimit searches deeper until the time runs out. it is checked
+// between nodes, so a move can take slightly longer
+func WithTimeLimit(timeLimit time.Duration) func(*Engine) {
+	return func(e *Engine) {
+		e.timeLimit = timeLimit
+	}
+}
+
+func WithEvaluator(evaluator Evaluator) func(*Engine) {
+	return func(e *Engine) {
+		e.evaluator = evaluator
+	}
+}
+
+// WithTableSize sets how many positions the transposition table holds
+func WithTableSize(entries int) func(*Engine) {
+	return func(e *Engine) {
+		e.tt = newTranspositionTable(max(entries, 1))
+	}
+}
+
+// SetPosition sets the position to search, from the game's starting FEN and
+// the moves played since
+func (e *Engine) SetPosition(startingFEN string, moves []move.Move, opts ...func(*state.FENOptions)) error {
+	s, err := state.ParseFEN(startingFEN, append([]func(*state.FENOptions){state.WithLenientFEN()}, opts...)...)
+	if err != nil {
+		return err
+	}
+
+	e.history = append(e.history[:0], s.Hash())
+	for _, m := range moves {
+		s.MakeMove(m)
+		e.history = append(e.history, s.Hash())
+	}
+
+	e.position = s.Position()
+
+	return nil
+}
+
+// GetMove plays the best move found before ctx is done, and only fails if
+// it is cancelled before the first iteration of the search completes
+func (e *Engine) GetMove(ctx context.Context, game player.GameView) (move.Move, error) {
+	variant, err := state.ParseVariant(game.Variant())
+	if err != nil {
+		return move.Move{}, err
+	}
+
+	opts := []func(*state.FENOptions){state.WithVariant(variant)}
+	if game.Chess960() {
+		opts = append(opts, state.WithChess960())
+	}
+
+	if err := e.SetPosition(game.StartingFEN(), game.MoveHistory(), opts...); err != nil {
+		return move.Move{}, err
+	}
+
+	// the search plays the other variants as standard chess after the first
+	// move, which is better than nothing
+	e.rootMoves = nil
+	if variant != state.Standard && variant != state.Crazyhouse {
+		e.rootMoves = game.ValidMoves()
+	}
+
+	depth, timeLimit := e.depth, e.timeLimit
+	if e.limits != (player.SearchLimits{}) {
+		depth, timeLimit = maxDepth, e.limits.MoveTime
+		if e.limits.Depth > 0 {
+			depth = min(e.limits.Depth, maxDepth)
+		}
+	}
+
+	// spend a fraction of what's left on the clock, in case the game is long
+	if left, ok := game.TimeLeft(game.Turn()); ok && !e.limits.Infinite && (timeLimit == 0 || left/movesToGo < timeLimit) {
+		timeLimit = left / movesToGo
+	}
+
+	best, _, err := e.search(ctx, depth, timeLimit, e.limits.Nodes)
+	if err != nil {
+		return move.Move{}, err
+	}
+
+	// none of the variant's moves were legal in standard chess
+	if len(e.rootMoves) > 0 && !slices.Contains(e.rootMoves, best.ToMove()) {
+		return e.rootMoves[0], nil
+	}
+
+	return best.ToMove(), nil
+}
+
+// SetSearchLimits replaces the engine's own depth and time limit for the
+// moves that follow, until it is given no limits
+func (e *Engine) SetSearchLimits(limits player.SearchLimits) {
+	e.limits = limits
+}
+
+// NewGame forgets everything learned in the previous game
+func (e *Engine) NewGame() {
+	e.tt.clear()
+}
+
+func (e *Engine) IsBot() bool {
+	return true
+}
+
+func (e *Engine) String() string {
+	if e.timeLimit > 0 {
+		return fmt.Sprintf("Engine(%s)", e.timeLimit)
+	}
+
+	return fmt.Sprintf("Engine(depth %d)", e.depth)
+}
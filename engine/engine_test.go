@@ -0,0 +1,243 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/clock"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/player"
+	"github.com/ethansaxenian/chess/state"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ player.Player = &Engine{}
+
+func getMove(e *Engine, fen string, moves ...string) move.Move {
+	s, err := state.ParseFEN(fen)
+	if err != nil {
+		panic(err)
+	}
+	s.PlayMoves(moves)
+
+	m, err := e.GetMove(context.Background(), s.View())
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func TestGetMove(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		expected string
+	}{
+		"back rank mate": {
+			fen:      "6k1/5ppp/8/8/8/8/5PPP/R5K1 w - - 0 1",
+			expected: "a1a8",
+		},
+		"scholar's mate": {
+			fen:      "r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4",
+			expected: "h5f7",
+		},
+		"hanging queen": {
+			fen:      "4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1",
+			expected: "d2d5",
+		},
+		"defended queen is not taken with the queen": {
+			fen:      "4k3/4p3/3p4/8/8/8/8/3QK3 w - - 0 1",
+			expected: "",
+		},
+		"mate in two": {
+			fen:      "r2qkb1r/pp2nppp/3p4/2pNN1B1/2BnP3/3P4/PPP2PPP/R2bK2R w KQkq - 1 10",
+			expected: "d5f6",
+		},
+		"promotion": {
+			fen:      "8/P6k/8/8/8/8/8/7K w - - 0 1",
+			expected: "a7a8q",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := getMove(New(WithDepth(4)), test.fen)
+			if test.expected == "" {
+				assert.NotEqual(t, "d1d6", m.String())
+			} else {
+				assert.Equal(t, test.expected, m.String())
+			}
+		})
+	}
+}
+
+func TestSearchScoresMate(t *testing.T) {
+	e := New(WithDepth(5))
+	getMove(e, "r2qkb1r/pp2nppp/3p4/2pNN1B1/2BnP3/3P4/PPP2PPP/R2bK2R w KQkq - 1 10")
+
+	_, score, err := e.Search(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, mateScore-3, score)
+}
+
+func TestSearchAvoidsRepetitionWhenWinning(t *testing.T) {
+	fen := "4k3/8/8/8/8/8/3Q4/4K3 w - - 0 1"
+	shuffle := []string{"d2d3", "e8f8", "d3d2", "f8e8", "d2d3", "e8f8"}
+
+	// d3d2 would repeat the position for the third time
+	m := getMove(New(WithDepth(3)), fen, shuffle...)
+	assert.NotEqual(t, "d3d2", m.String())
+}
+
+func TestWithTimeLimit(t *testing.T) {
+	e := New(WithTimeLimit(50 * time.Millisecond))
+
+	start := time.Now()
+	m := getMove(e, board.StartingFEN)
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.NotEqual(t, move.Move{}, m)
+}
+
+type countingEvaluator struct {
+	calls int
+}
+
+func (c *countingEvaluator) Evaluate(p *bitboard.Position) int {
+	c.calls++
+	return 0
+}
+
+func TestWithEvaluator(t *testing.T) {
+	c := &countingEvaluator{}
+	getMove(New(WithDepth(2), WithEvaluator(c)), board.StartingFEN)
+	assert.Positive(t, c.calls)
+}
+
+func TestGetMoveCancelled(t *testing.T) {
+	s := state.NewStartingTestState(nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := New(WithDepth(10)).GetMove(ctx, s.View())
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGetMoveStopsAtDeadline(t *testing.T) {
+	s := state.NewStartingTestState(nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	m, err := New(WithTimeLimit(time.Minute)).GetMove(ctx, s.View())
+	assert.NoError(t, err)
+	assert.Contains(t, s.GeneratePossibleMoves(), m)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestSelfPlay(t *testing.T) {
+	e := New(WithDepth(2))
+	s := state.StartingState(e, e)
+
+	for range 20 {
+		if _, over := s.CheckGameOver(); over {
+			break
+		}
+
+		m, err := s.ActivePlayerMove(context.Background(), s.GeneratePossibleMoves())
+		assert.NoError(t, err)
+		s.MakeMove(m)
+	}
+}
+
+func TestChess960SelfPlay(t *testing.T) {
+	fen, err := board.Chess960FEN(0)
+	assert.NoError(t, err)
+
+	e := New(WithDepth(2))
+	s := state.StartingStateFromFEN(fen, e, e, state.WithChess960())
+
+	for range 20 {
+		if _, over := s.CheckGameOver(); over {
+			break
+		}
+
+		possibleMoves := s.GeneratePossibleMoves()
+		m, err := s.ActivePlayerMove(context.Background(), possibleMoves)
+		assert.NoError(t, err)
+		assert.Contains(t, possibleMoves, m)
+		s.MakeMove(m)
+	}
+}
+
+// the search only knows crazyhouse, but must play legal moves in every variant
+func TestVariantSelfPlay(t *testing.T) {
+	for _, v := range []state.Variant{state.Crazyhouse, state.ThreeCheck, state.KingOfTheHill, state.Atomic, state.Antichess} {
+		t.Run(v.String(), func(t *testing.T) {
+			e := New(WithDepth(2))
+			s := state.StartingStateFromFEN(board.StartingFEN, e, e, state.WithVariant(v))
+
+			for range 30 {
+				if _, over := s.CheckGameOver(); over {
+					break
+				}
+
+				m, err := s.ActivePlayerMove(context.Background(), s.GeneratePossibleMoves())
+				assert.NoError(t, err)
+				s.MakeMove(m)
+			}
+		})
+	}
+}
+
+func TestGetMoveBudgetsClock(t *testing.T) {
+	e := New(WithTimeLimit(time.Minute))
+	s := state.StartingState(e, e)
+	s.StartClock(clock.New(clock.SuddenDeath(3*time.Second, 0)))
+
+	start := time.Now()
+	m, err := s.ActivePlayerMove(context.Background(), s.GeneratePossibleMoves())
+	assert.NoError(t, err)
+	assert.NotEqual(t, move.Move{}, m)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestSetSearchLimits(t *testing.T) {
+	tests := map[string]player.SearchLimits{
+		"depth":     {Depth: 2},
+		"nodes":     {Nodes: 10000},
+		"move time": {MoveTime: 50 * time.Millisecond},
+	}
+
+	for name, limits := range tests {
+		t.Run(name, func(t *testing.T) {
+			// the limits replace the engine's own minute
+			e := New(WithTimeLimit(time.Minute))
+			e.SetSearchLimits(limits)
+
+			start := time.Now()
+			m := getMove(e, board.StartingFEN)
+			assert.NotEqual(t, move.Move{}, m)
+			assert.Less(t, time.Since(start), time.Second)
+		})
+	}
+}
+
+func TestInfiniteSearch(t *testing.T) {
+	e := New(WithTimeLimit(50 * time.Millisecond))
+	e.SetSearchLimits(player.SearchLimits{Infinite: true})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	// still searching long after its own time limit, until it's stopped
+	start := time.Now()
+	m, err := e.GetMove(ctx, state.NewStartingTestState(nil, nil).View())
+	assert.NoError(t, err)
+	assert.NotEqual(t, move.Move{}, m)
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+}
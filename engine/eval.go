@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/piece"
+)
+
+// Evaluator scores a quiet position in centipawns from the point of view of
+// the side to move
+type Evaluator interface {
+	Evaluate(p *bitboard.Position) int
+}
+
+var pieceValues = [7]int{
+	piece.Pawn:   100,
+	piece.Knight: 320,
+	piece.Bishop: 330,
+	piece.Rook:   500,
+	piece.Queen:  900,
+	piece.King:   20000,
+}
+
+// piece-square tables are written from white's side with a8 first, so they
+// read like a board diagram
+var pieceSquareTables = [7][64]int{
+	piece.Pawn: {
+		0, 0, 0, 0, 0, 0, 0, 0,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		10, 10, 20, 30, 30, 20, 10, 10,
+		5, 5, 10, 25, 25, 10, 5, 5,
+		0, 0, 0, 20, 20, 0, 0, 0,
+		5, -5, -10, 0, 0, -10, -5, 5,
+		5, 10, 10, -20, -20, 10, 10, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	},
+	piece.Knight: {
+		-50, -40, -30, -30, -30, -30, -40, -50,
+		-40, -20, 0, 0, 0, 0, -20, -40,
+		-30, 0, 10, 15, 15, 10, 0, -30,
+		-30, 5, 15, 20, 20, 15, 5, -30,
+		-30, 0, 15, 20, 20, 15, 0, -30,
+		-30, 5, 10, 15, 15, 10, 5, -30,
+		-40, -20, 0, 5, 5, 0, -20, -40,
+		-50, -40, -30, -30, -30, -30, -40, -50,
+	},
+	piece.Bishop: {
+		-20, -10, -10, -10, -10, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 10, 10, 5, 0, -10,
+		-10, 5, 5, 10, 10, 5, 5, -10,
+		-10, 0, 10, 10, 10, 10, 0, -10,
+		-10, 10, 10, 10, 10, 10, 10, -10,
+		-10, 5, 0, 0, 0, 0, 5, -10,
+		-20, -10, -10, -10, -10, -10, -10, -20,
+	},
+	piece.Rook: {
+		0, 0, 0, 0, 0, 0, 0, 0,
+		5, 10, 10, 10, 10, 10, 10, 5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		0, 0, 0, 5, 5, 0, 0, 0,
+	},
+	piece.Queen: {
+		-20, -10, -10, -5, -5, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 5, 5, 5, 0, -10,
+		-5, 0, 5, 5, 5, 5, 0, -5,
+		0, 0, 5, 5, 5, 5, 0, -5,
+		-10, 5, 5, 5, 5, 5, 0, -10,
+		-10, 0, 5, 0, 0, 0, 0, -10,
+		-20, -10, -10, -5, -5, -10, -10, -20,
+	},
+	piece.King: {
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-20, -30, -30, -40, -40, -30, -30, -20,
+		-10, -20, -20, -20, -20, -20, -20, -10,
+		20, 20, 0, 0, 0, 0, 20, 20,
+		20, 30, 10, 0, 0, 10, 30, 20,
+	},
+}
+
+// the king should come out once the queens and most pieces are gone
+var kingEndgameTable = [64]int{
+	-50, -40, -30, -20, -20, -30, -40, -50,
+	-30, -20, -10, 0, 0, -10, -20, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -30, 0, 0, 0, 0, -30, -30,
+	-50, -30, -30, -30, -30, -30, -30, -50,
+}
+
+// phaseWeights add up to maxPhase with all the pieces on the board
+var phaseWeights = [7]int{
+	piece.Knight: 1,
+	piece.Bishop: 1,
+	piece.Rook:   2,
+	piece.Queen:  4,
+}
+
+const maxPhase = 24
+
+// tableIndex maps a square to its place in a piece-square table
+func tableIndex(sq int, color piece.Piece) int {
+	if color == piece.Black {
+		return sq
+	}
+
+	return (7-sq/8)*8 + sq%8
+}
+
+// PieceSquareEvaluator counts material and adds a bonus or penalty for where
+// each piece stands. the king's table blends towards the endgame one as
+// pieces come off. pieces in crazyhouse pockets count at their plain value
+type PieceSquareEvaluator struct{}
+
+func (PieceSquareEvaluator) Evaluate(p *bitboard.Position) int {
+	var score, phase int
+	var kingScore, kingEndgameScore int
+
+	for _, color := range piece.AllColors {
+		sign := int(color)
+
+		for pieceType := piece.Pawn; pieceType <= piece.King; pieceType++ {
+			pieces := p.Pieces(color, pieceType)
+			phase += phaseWeights[pieceType] * pieces.Count()
+
+			for pieces != 0 {
+				i := tableIndex(pieces.PopLSB(), color)
+
+				if pieceType == piece.King {
+					kingScore += sign * pieceSquareTables[piece.King][i]
+					kingEndgameScore += sign * kingEndgameTable[i]
+					continue
+				}
+
+				score += sign * (pieceValues[pieceType] + pieceSquareTables[pieceType][i])
+			}
+		}
+
+		pocket := p.Pocket(color)
+		for pieceType := piece.Pawn; pieceType < piece.King; pieceType++ {
+			score += sign * pieceValues[pieceType] * pocket[pieceType]
+		}
+	}
+
+	phase = min(phase, maxPhase)
+	score += (kingScore*phase + kingEndgameScore*(maxPhase-phase)) / maxPhase
+
+	return score * int(p.SideToMove())
+}
@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func evaluate(fen string) int {
+	s, err := state.ParseFEN(fen)
+	if err != nil {
+		panic(err)
+	}
+
+	p := s.Position()
+	return PieceSquareEvaluator{}.Evaluate(&p)
+}
+
+// mirror flips the board and swaps the colors of a FEN without castling or en passant
+func mirror(fen string) string {
+	fields := strings.Fields(fen)
+
+	ranks := strings.Split(fields[0], "/")
+	for i, j := 0, len(ranks)-1; i < j; i, j = i+1, j-1 {
+		ranks[i], ranks[j] = ranks[j], ranks[i]
+	}
+
+	swapped := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return r
+	}, strings.Join(ranks, "/"))
+
+	color := "w"
+	if fields[1] == "w" {
+		color = "b"
+	}
+
+	return strings.Join([]string{swapped, color, "-", "-", fields[4], fields[5]}, " ")
+}
+
+func TestEvaluateIsSymmetric(t *testing.T) {
+	assert.Equal(t, 0, evaluate(board.StartingFEN))
+
+	fens := []string{
+		"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w - - 4 4",
+		"4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	}
+
+	for _, fen := range fens {
+		t.Run(fen, func(t *testing.T) {
+			assert.Equal(t, evaluate(fen), evaluate(mirror(fen)))
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	// an extra queen is worth a lot, for whoever is to move
+	up := evaluate("3qk3/8/8/8/8/8/8/4K3 b - - 0 1")
+	assert.Greater(t, up, 800)
+	assert.Equal(t, -up, evaluate("3qk3/8/8/8/8/8/8/4K3 w - - 0 1"))
+
+	// centralized knights beat knights on the rim
+	assert.Greater(t, evaluate("4k3/8/8/8/3N4/8/8/4K3 w - - 0 1"), evaluate("4k3/8/8/8/N7/8/8/4K3 w - - 0 1"))
+
+	// in the endgame the king belongs in the center
+	assert.Greater(t, evaluate("7k/8/8/8/3K4/8/8/8 w - - 0 1"), evaluate("7k/8/8/8/8/8/8/K7 w - - 0 1"))
+}
@@ -0,0 +1,320 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/piece"
+)
+
+const (
+	mateScore     = 100000
+	mateThreshold = mateScore - 1000
+	infinity      = mateScore + 1
+	maxPly        = 128
+
+	// how many nodes to search between looking at the clock
+	timeCheckInterval = 2048
+)
+
+type searcher struct {
+	e        *Engine
+	ctx      context.Context
+	deadline time.Time
+	depth    int
+	nodes    int
+	maxNodes int
+	stopped  bool
+
+	// the game so far followed by the current line
+	path     []uint64
+	rootBest bitboard.Move
+
+	killers [maxPly][2]bitboard.Move
+	history [64][64]int
+}
+
+// Search finds the best move in the position set by SetPosition, and its
+// score in centipawns for the side to move. it stops early when ctx is done
+func (e *Engine) Search(ctx context.Context) (bitboard.Move, int, error) {
+	return e.search(ctx, e.depth, e.timeLimit, 0)
+}
+
+// search goes as deep as depth, for as long as timeLimit, and stops after
+// about maxNodes nodes. 0 is no limit on time or nodes
+func (e *Engine) search(ctx context.Context, depth int, timeLimit time.Duration, maxNodes int) (bitboard.Move, int, error) {
+	if err := ctx.Err(); err != nil {
+		return bitboard.Move{}, 0, err
+	}
+
+	s := &searcher{
+		e:        e,
+		ctx:      ctx,
+		maxNodes: maxNodes,
+		path:     slices.Clone(e.history),
+	}
+
+	start := time.Now()
+	if timeLimit > 0 {
+		s.deadline = start.Add(timeLimit)
+	}
+	if deadline, ok := ctx.Deadline(); ok && (s.deadline.IsZero() || deadline.Before(s.deadline)) {
+		s.deadline = deadline
+	}
+
+	var best bitboard.Move
+	var bestScore int
+	for s.depth = 1; s.depth <= depth; s.depth++ {
+		score := s.negamax(&e.position, s.depth, 0, -infinity, infinity)
+		if s.stopped {
+			if s.depth == 1 {
+				return bitboard.Move{}, 0, ctx.Err()
+			}
+			break
+		}
+
+		best, bestScore = s.rootBest, score
+		slog.Debug("engine: search", "depth", s.depth, "score", score, "move", best, "nodes", s.nodes, "time", time.Since(start))
+
+		// nothing deeper will find a faster mate
+		if score > mateThreshold || score < -mateThreshold {
+			break
+		}
+	}
+
+	return best, bestScore, nil
+}
+
+func (s *searcher) checkTime() {
+	if s.nodes%timeCheckInterval != 0 {
+		return
+	}
+
+	if s.ctx.Err() != nil {
+		s.stopped = true
+	}
+
+	// the first iteration always finishes so there is a move to play, unless
+	// the search is cancelled outright
+	if s.depth > 1 && !s.deadline.IsZero() && time.Now().After(s.deadline) {
+		s.stopped = true
+	}
+
+	// nodes are only counted here, so the limit is overshot a little
+	if s.depth > 1 && s.maxNodes > 0 && s.nodes >= s.maxNodes {
+		s.stopped = true
+	}
+}
+
+// isRepetition treats any repetition inside the search as a draw, since a
+// side that can force one once can force it again
+func (s *searcher) isRepetition(key uint64) bool {
+	// only positions with the same side to move can match
+	for i := len(s.path) - 3; i >= 0; i -= 2 {
+		if s.path[i] == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *searcher) negamax(p *bitboard.Position, depth, ply, alpha, beta int) int {
+	key := p.Hash()
+
+	if ply > 0 && (s.isRepetition(key) || p.InsufficientMaterial()) {
+		return 0
+	}
+
+	inCheck := p.InCheck()
+	if inCheck {
+		depth++
+	}
+
+	if depth <= 0 || ply >= maxPly {
+		return s.quiesce(p, ply, alpha, beta)
+	}
+
+	s.nodes++
+	s.checkTime()
+	if s.stopped {
+		return 0
+	}
+
+	var ttMove bitboard.Move
+	if entry, ok := s.e.tt.probe(key); ok {
+		ttMove = entry.move
+		score := scoreFromTT(entry.score, ply)
+
+		if ply > 0 && entry.depth >= depth {
+			switch {
+			case entry.bound == exact,
+				entry.bound == lowerBound && score >= beta,
+				entry.bound == upperBound && score <= alpha:
+				return score
+			}
+		}
+	}
+
+	moves := p.LegalMoves(make([]bitboard.Move, 0, 64))
+	if ply == 0 && s.e.rootMoves != nil {
+		moves = slices.DeleteFunc(moves, func(m bitboard.Move) bool {
+			return !slices.Contains(s.e.rootMoves, m.ToMove())
+		})
+	}
+	if len(moves) == 0 {
+		if inCheck {
+			return -mateScore + ply
+		}
+		return 0
+	}
+
+	s.orderMoves(p, moves, ttMove, ply)
+
+	originalAlpha := alpha
+	best := -infinity
+	var bestMove bitboard.Move
+
+	for _, m := range moves {
+		next := *p
+		next.MakeMove(m)
+
+		s.path = append(s.path, next.Hash())
+		score := -s.negamax(&next, depth-1, ply+1, -beta, -alpha)
+		s.path = s.path[:len(s.path)-1]
+
+		if s.stopped {
+			return 0
+		}
+
+		if score <= best {
+			continue
+		}
+
+		best, bestMove = score, m
+		if ply == 0 {
+			s.rootBest = m
+		}
+
+		if score > alpha {
+			alpha = score
+		}
+
+		if alpha >= beta {
+			if !m.IsCapture() && m.Promotion == piece.Empty {
+				s.killers[ply][1] = s.killers[ply][0]
+				s.killers[ply][0] = m
+				// crazyhouse drops have no square to keep a history for
+				if !m.IsDrop() {
+					s.history[m.From][m.To] += depth * depth
+				}
+			}
+			break
+		}
+	}
+
+	b := exact
+	if best <= originalAlpha {
+		b = upperBound
+	} else if best >= beta {
+		b = lowerBound
+	}
+	s.e.tt.store(key, bestMove, scoreToTT(best, ply), depth, b)
+
+	return best
+}
+
+// quiesce only follows captures and promotions, so the evaluation isn't taken
+// in the middle of an exchange
+func (s *searcher) quiesce(p *bitboard.Position, ply, alpha, beta int) int {
+	s.nodes++
+	s.checkTime()
+	if s.stopped {
+		return 0
+	}
+
+	inCheck := p.InCheck()
+
+	// standing pat isn't an option when in check
+	if !inCheck {
+		standPat := s.e.evaluator.Evaluate(p)
+		if standPat >= beta || ply >= maxPly {
+			return standPat
+		}
+		alpha = max(alpha, standPat)
+	}
+
+	moves := p.LegalMoves(make([]bitboard.Move, 0, 64))
+	if len(moves) == 0 {
+		if inCheck {
+			return -mateScore + ply
+		}
+		return 0
+	}
+
+	if !inCheck {
+		moves = slices.DeleteFunc(moves, func(m bitboard.Move) bool {
+			return !m.IsCapture() && m.Promotion == piece.Empty
+		})
+	}
+	s.orderMoves(p, moves, bitboard.Move{}, maxPly)
+
+	for _, m := range moves {
+		next := *p
+		next.MakeMove(m)
+
+		score := -s.quiesce(&next, ply+1, -beta, -alpha)
+		if s.stopped {
+			return 0
+		}
+
+		if score >= beta {
+			return score
+		}
+		alpha = max(alpha, score)
+	}
+
+	return alpha
+}
+
+// orderMoves searches the likeliest cutoffs first: the transposition table's
+// move, then captures of the most valuable victim by the least valuable
+// attacker, then killers and moves with a good history
+func (s *searcher) orderMoves(p *bitboard.Position, moves []bitboard.Move, ttMove bitboard.Move, ply int) {
+	scores := make([]int, len(moves))
+
+	for i, m := range moves {
+		var score int
+		switch {
+		case m == ttMove:
+			score = 1 << 30
+		case m.IsCapture() || m.Promotion != piece.Empty:
+			// en passant leaves the target square empty
+			victim := max(p.Piece(m.To).Type(), piece.Pawn)
+			if !m.IsCapture() {
+				victim = piece.Empty
+			}
+			score = 1<<20 + pieceValues[victim]*16 + pieceValues[m.Promotion] - int(p.Piece(m.From).Type())
+		case ply < maxPly && m == s.killers[ply][0]:
+			score = 1<<19 + 1
+		case ply < maxPly && m == s.killers[ply][1]:
+			score = 1 << 19
+		case m.IsDrop():
+			score = 0
+		default:
+			score = s.history[m.From][m.To]
+		}
+		scores[i] = score
+	}
+
+	// insertion sort, since move lists are short and the scores move with them
+	for i := 1; i < len(moves); i++ {
+		for j := i; j > 0 && scores[j] > scores[j-1]; j-- {
+			moves[j], moves[j-1] = moves[j-1], moves[j]
+			scores[j], scores[j-1] = scores[j-1], scores[j]
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"github.com/ethansaxenian/chess/bitboard"
+)
+
+type bound uint8
+
+const (
+	exact bound = iota
+	lowerBound
+	upperBound
+)
+
+type ttEntry struct {
+	key   uint64
+	move  bitboard.Move
+	score int
+	depth int
+	bound bound
+}
+
+// transpositionTable remembers searched positions by zobrist key. a slot is
+// simply overwritten when another position hashes to it
+type transpositionTable struct {
+	entries []ttEntry
+	mask    uint64
+}
+
+// newTranspositionTable rounds size down to a power of two
+func newTranspositionTable(size int) *transpositionTable {
+	n := 1
+	for n*2 <= size {
+		n *= 2
+	}
+
+	return &transpositionTable{make([]ttEntry, n), uint64(n - 1)}
+}
+
+func (t *transpositionTable) probe(key uint64) (ttEntry, bool) {
+	e := t.entries[key&t.mask]
+	return e, e.key == key
+}
+
+func (t *transpositionTable) store(key uint64, m bitboard.Move, score, depth int, b bound) {
+	e := &t.entries[key&t.mask]
+
+	// keep the deeper result for the same position
+	if e.key == key && e.depth > depth && b != exact {
+		return
+	}
+
+	*e = ttEntry{key, m, score, depth, b}
+}
+
+func (t *transpositionTable) clear() {
+	clear(t.entries)
+}
+
+// mate scores depend on how far the mate is from the root, so they are stored
+// relative to the position instead
+func scoreToTT(score, ply int) int {
+	if score > mateThreshold {
+		return score + ply
+	} else if score < -mateThreshold {
+		return score - ply
+	}
+
+	return score
+}
+
+func scoreFromTT(score, ply int) int {
+	if score > mateThreshold {
+		return score - ply
+	} else if score < -mateThreshold {
+		return score + ply
+	}
+
+	return score
+}
@@ -1,22 +1,30 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
 	"os"
+	"os/signal"
 	"runtime/pprof"
-	"slices"
 	"strings"
 
 	"github.com/ethansaxenian/chess/assert"
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/clock"
+	"github.com/ethansaxenian/chess/engine"
 	"github.com/ethansaxenian/chess/player"
+	"github.com/ethansaxenian/chess/polyglot"
 	"github.com/ethansaxenian/chess/state"
 	"github.com/ethansaxenian/chess/tui"
+	"github.com/ethansaxenian/chess/uci"
 )
 
-func initLogger(value string) {
+func initLogger(value string, w io.Writer) {
 	var level slog.Level
 	switch strings.ToLower(value) {
 	case "error":
@@ -32,34 +40,95 @@ func initLogger(value string) {
 	}
 
 	h := slog.NewTextHandler(
-		os.Stdout,
+		w,
 		&slog.HandlerOptions{Level: level},
 	)
 
 	slog.SetDefault(slog.New(h))
 }
 
-func mainLoop(state *state.State) {
+func mainLoop(ctx context.Context, state *state.State) error {
 	if res, over := state.CheckGameOver(); over {
 		fmt.Println(res)
 		os.Exit(0)
 	}
 
 	state.Print()
+
+	if res, claimed := state.ClaimDraw(); claimed {
+		fmt.Println(res)
+		os.Exit(0)
+	}
+
 	possibleMoves := state.GeneratePossibleMoves()
 	assert.AddContext("possible moves", possibleMoves)
 	assert.AddContext("FEN", state.FEN())
 	assert.AddContext("moves", state.Moves)
 
-	m := state.ActivePlayer().GetMove(possibleMoves)
-	assert.Assert(slices.Contains(possibleMoves, m), fmt.Sprintf("%s not in possibleMoves", m))
+	// the player is stopped when their flag falls, and the next check ends the game
+	moveCtx, cancel := state.TurnContext(ctx)
+	defer cancel()
+
+	m, err := state.ActivePlayerMove(moveCtx, possibleMoves)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
 	state.MakeMove(m)
+
+	return nil
+}
+
+func newBot(name string, book *polyglot.Book) player.Player {
+	var bot player.Player
+	switch name {
+	case "random":
+		bot = player.NewRandoBot()
+	case "engine":
+		bot = engine.New()
+	default:
+		log.Fatalf("invalid bot: %s\n", name)
+	}
+
+	if book != nil {
+		return player.NewBookPlayer(book, bot)
+	}
+
+	return bot
+}
+
+func runPerft(args []string) {
+	perftFlags := flag.NewFlagSet("perft", flag.ExitOnError)
+	depth := perftFlags.Int("depth", 3, "number of plies to search")
+	fen := perftFlags.String("fen", board.StartingFEN, "position to search from")
+	chess960 := perftFlags.Bool("chess960", false, "read the castling rights as chess960 (X-FEN or Shredder-FEN)")
+	perftFlags.Parse(args)
+
+	opts := []func(*state.FENOptions){state.WithLenientFEN()}
+	if *chess960 {
+		opts = append(opts, state.WithChess960())
+	}
+
+	s, err := state.ParseFEN(*fen, opts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(state.FormatDivide(s.Divide(*depth)))
 }
 
 func main() {
 	var logLevel = flag.String("log-level", "info", "set the log level (debug, info, warning, error)")
 	var cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
 	var useTUI = flag.Bool("tui", false, "use the bubbletea tui")
+	var bot = flag.String("bot", "random", "set the bot that plays (random, engine)")
+	var deadPositions = flag.Bool("dead-positions", false, "also end games in blocked pawn positions neither side can win")
+	var bookPath = flag.String("book", "", "play openings from a polyglot book")
+	var timeControl = flag.String("tc", "", "play with a clock, e.g. 5+3, 15d5 or 40/90+30,30+30 (minutes+seconds)")
+	var chess960 = flag.Int("chess960", -1, fmt.Sprintf("play chess960 from start position 0-%d (518 is the standard one)", board.Chess960Positions-1))
+	var variantName = flag.String("variant", "chess", "set the rules (chess, crazyhouse, 3check, kingofthehill, atomic, antichess)")
 	flag.Parse()
 
 	if *cpuprofile != "" {
@@ -71,19 +140,81 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
-	initLogger(*logLevel)
+	var book *polyglot.Book
+	if *bookPath != "" {
+		var err error
+		if book, err = polyglot.Open(*bookPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if flag.Arg(0) == "perft" {
+		initLogger(*logLevel, os.Stderr)
+		runPerft(flag.Args()[1:])
+		return
+	}
+
+	// uci mode owns stdout, so logs go to stderr
+	if flag.Arg(0) == "uci" {
+		initLogger(*logLevel, os.Stderr)
+		if err := uci.NewServer(newBot(*bot, book)).Run(os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	initLogger(*logLevel, os.Stdout)
 
 	// white := player.NewHumanPlayer("human")
 	// black := player.NewHumanPlayer("human")
-	white := player.NewRandoBot()
-	black := player.NewRandoBot()
+	white := newBot(*bot, book)
+	black := newBot(*bot, book)
+
+	var c *clock.Clock
+	if *timeControl != "" {
+		tc, err := clock.ParseTimeControl(*timeControl)
+		if err != nil {
+			log.Fatal(err)
+		}
+		c = clock.New(tc)
+	}
+
+	variant, err := state.ParseVariant(*variantName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fen := board.StartingFEN
+	opts := []func(*state.FENOptions){state.WithVariant(variant)}
+	if *chess960 >= 0 {
+		var err error
+		if fen, err = board.Chess960FEN(*chess960); err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, state.WithChess960())
+	}
+	s := state.StartingStateFromFEN(fen, white, black, opts...)
+
+	s.DeadPositions = *deadPositions
+	if c != nil {
+		s.StartClock(c)
+	}
 
 	if *useTUI {
-		tui.RunTUI(white, black)
-	} else {
-		s := state.StartingState(white, black)
-		for {
-			mainLoop(s)
+		tui.RunTUI(s)
+		return
+	}
+
+	// ctrl-c stops whoever is thinking instead of killing the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	for {
+		if err := mainLoop(ctx, s); err != nil {
+			if ctx.Err() != nil {
+				fmt.Println("game aborted")
+				return
+			}
+			log.Fatal(err)
 		}
 	}
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"testing"
 
 	"github.com/ethansaxenian/chess/player"
@@ -15,7 +16,7 @@ func TestMainLoop(t *testing.T) {
 	s := state.NewStartingTestState(white, black)
 
 	for i := 0; i < 1; i++ {
-		mainLoop(s)
+		assert.NoError(t, mainLoop(context.Background(), s))
 	}
 
 	firstFEN := s.FEN()
@@ -26,7 +27,7 @@ func TestMainLoop(t *testing.T) {
 	s = state.NewStartingTestState(white, black)
 
 	for i := 0; i < 1; i++ {
-		mainLoop(s)
+		assert.NoError(t, mainLoop(context.Background(), s))
 	}
 
 	secondFEN := s.FEN()
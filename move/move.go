@@ -2,14 +2,20 @@ package move
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/ethansaxenian/chess/assert"
+	"github.com/ethansaxenian/chess/piece"
 )
 
 type Move struct {
 	Source, Target string
+	Promotion      piece.Piece
+	// Drop is the piece put on the target in crazyhouse, which has no source
+	Drop piece.Piece
 }
 
 func (m Move) SourceRank() int {
@@ -36,7 +42,86 @@ func NewMove(source, target string) Move {
 	return Move{Source: source, Target: target}
 }
 
+func NewPromotionMove(source, target string, promotion piece.Piece) Move {
+	return Move{Source: source, Target: target, Promotion: promotion.Type()}
+}
+
+func NewDropMove(p piece.Piece, target string) Move {
+	return Move{Target: target, Drop: p.Type()}
+}
+
+func (m Move) IsDrop() bool {
+	return m.Drop != piece.Empty
+}
+
+func isSquare(s string) bool {
+	return len(s) == 2 && s[0] >= 'a' && s[0] <= 'h' && s[1] >= '1' && s[1] <= '8'
+}
+
+// ParseError says which part of a move in long algebraic notation is invalid:
+// "length", "source", "target", "promotion" or "drop"
+type ParseError struct {
+	Move  string
+	Field string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid move %q: bad %s", e.Move, e.Field)
+}
+
+// ParseMove reads long algebraic notation, e.g. e2e4 or e7e8q, and drops
+// written as P@e4
+func ParseMove(s string) (Move, error) {
+	s = strings.ToLower(s)
+
+	if len(s) == 4 && s[1] == '@' {
+		p, ok := piece.CharToPiece[rune(s[0])]
+		if !ok || p == piece.Empty || p == piece.King {
+			return Move{}, &ParseError{s, "drop"}
+		}
+
+		if !isSquare(s[2:]) {
+			return Move{}, &ParseError{s, "target"}
+		}
+
+		return NewDropMove(p, s[2:]), nil
+	}
+
+	if len(s) != 4 && len(s) != 5 {
+		return Move{}, &ParseError{s, "length"}
+	}
+
+	if !isSquare(s[:2]) {
+		return Move{}, &ParseError{s, "source"}
+	}
+
+	if !isSquare(s[2:4]) {
+		return Move{}, &ParseError{s, "target"}
+	}
+
+	m := NewMove(s[:2], s[2:4])
+
+	if len(s) == 5 {
+		p, ok := piece.CharToPiece[rune(s[4])]
+		// antichess pawns can promote to a king, which is only legal there
+		if !ok || (!slices.Contains(piece.PossiblePromotions, p) && p != piece.King) {
+			return Move{}, &ParseError{s, "promotion"}
+		}
+		m.Promotion = p
+	}
+
+	return m, nil
+}
+
 func (m Move) String() string {
+	if m.IsDrop() {
+		return m.Drop.FEN() + "@" + m.Target
+	}
+
+	if m.Promotion != piece.Empty {
+		return m.Source + m.Target + strings.ToLower(m.Promotion.FEN())
+	}
+
 	return m.Source + m.Target
 }
 
@@ -3,6 +3,7 @@ package move
 import (
 	"testing"
 
+	"github.com/ethansaxenian/chess/piece"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -12,3 +13,57 @@ func TestSortMoves(t *testing.T) {
 	expected := []Move{NewMove("a1", "h8"), NewMove("h8", "a1")}
 	assert.Equal(t, expected, moves)
 }
+
+func TestPromotionString(t *testing.T) {
+	assert.Equal(t, "e7e8q", NewPromotionMove("e7", "e8", piece.Queen).String())
+	assert.Equal(t, "a2a1n", NewPromotionMove("a2", "a1", piece.Knight*piece.Black).String())
+	assert.Equal(t, "e2e4", NewMove("e2", "e4").String())
+}
+
+func TestDropString(t *testing.T) {
+	assert.Equal(t, "P@e4", NewDropMove(piece.Pawn, "e4").String())
+	assert.Equal(t, "N@f6", NewDropMove(piece.Knight*piece.Black, "f6").String())
+	assert.True(t, NewDropMove(piece.Queen, "d5").IsDrop())
+	assert.False(t, NewMove("e2", "e4").IsDrop())
+}
+
+func TestParseMove(t *testing.T) {
+	m, err := ParseMove("e2e4")
+	assert.NoError(t, err)
+	assert.Equal(t, NewMove("e2", "e4"), m)
+
+	m, err = ParseMove("e7e8N")
+	assert.NoError(t, err)
+	assert.Equal(t, NewPromotionMove("e7", "e8", piece.Knight), m)
+
+	// antichess pawns can promote to a king
+	m, err = ParseMove("e7e8k")
+	assert.NoError(t, err)
+	assert.Equal(t, NewPromotionMove("e7", "e8", piece.King), m)
+
+	m, err = ParseMove("n@f3")
+	assert.NoError(t, err)
+	assert.Equal(t, NewDropMove(piece.Knight, "f3"), m)
+
+	invalid := map[string]string{
+		"":       "length",
+		"e2":     "length",
+		"e7e8qq": "length",
+		"e2e9":   "target",
+		"i2e4":   "source",
+		"e7e8p":  "promotion",
+		"e7e8_":  "promotion",
+		"K@e4":   "drop",
+		"x@e4":   "drop",
+		"P@e9":   "target",
+	}
+
+	for input, field := range invalid {
+		_, err = ParseMove(input)
+
+		var parseErr *ParseError
+		if assert.ErrorAs(t, err, &parseErr, input) {
+			assert.Equal(t, field, parseErr.Field, input)
+		}
+	}
+}
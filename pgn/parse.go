@@ -0,0 +1,333 @@
+package pgn
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/ethansaxenian/chess/player"
+	"github.com/ethansaxenian/chess/state"
+)
+
+var ErrSyntax = errors.New("pgn syntax error")
+
+var moveNumberPattern = regexp.MustCompile(`^[0-9]+\.*`)
+
+var resultTokens = []string{"1-0", "0-1", "1/2-1/2", "*"}
+
+// namedPlayer stands in for the players named in the PGN tags
+type namedPlayer struct {
+	name string
+}
+
+func (p *namedPlayer) GetMove(context.Context, player.GameView) (move.Move, error) {
+	return move.Move{}, fmt.Errorf("pgn player %s cannot choose moves", p.name)
+}
+
+func (p *namedPlayer) IsBot() bool {
+	return false
+}
+
+func (p *namedPlayer) String() string {
+	return p.name
+}
+
+type parser struct {
+	r     *bufio.Reader
+	line  int
+	games []Game
+	curr  *Game
+	depth int
+}
+
+func Parse(r io.Reader) ([]Game, error) {
+	p := &parser{r: bufio.NewReader(r), line: 1}
+
+	if err := p.parse(); err != nil {
+		return p.games, err
+	}
+
+	return p.games, nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", ErrSyntax, p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) next() (rune, bool) {
+	c, _, err := p.r.ReadRune()
+	if err != nil {
+		return 0, false
+	}
+
+	if c == '\n' {
+		p.line++
+	}
+
+	return c, true
+}
+
+func (p *parser) peek() (rune, bool) {
+	c, _, err := p.r.ReadRune()
+	if err != nil {
+		return 0, false
+	}
+
+	p.r.UnreadRune()
+	return c, true
+}
+
+func (p *parser) skipUntil(end rune) bool {
+	for {
+		c, ok := p.next()
+		if !ok {
+			return false
+		}
+
+		if c == end {
+			return true
+		}
+	}
+}
+
+func (p *parser) parse() error {
+	lineStart := true
+
+	for {
+		c, ok := p.next()
+		if !ok {
+			return p.finishGame()
+		}
+
+		switch {
+		case c == '\n':
+			lineStart = true
+			continue
+		case unicode.IsSpace(c):
+			continue
+		case c == '%' && lineStart:
+			p.skipUntil('\n')
+		case c == '[':
+			if err := p.parseTag(); err != nil {
+				return err
+			}
+		case c == '{':
+			if !p.skipUntil('}') {
+				return p.errorf("unterminated comment")
+			}
+		case c == ';':
+			p.skipUntil('\n')
+		case c == '(':
+			p.depth++
+		case c == ')':
+			if p.depth == 0 {
+				return p.errorf("unexpected ')'")
+			}
+			p.depth--
+		case c == '$':
+			p.readSymbol()
+		default:
+			p.r.UnreadRune()
+			if err := p.parseSymbol(p.readSymbol()); err != nil {
+				return err
+			}
+		}
+
+		lineStart = c == '\n'
+	}
+}
+
+func (p *parser) readSymbol() string {
+	var sb strings.Builder
+	for {
+		c, ok := p.peek()
+		if !ok || unicode.IsSpace(c) || strings.ContainsRune("[]{}();$", c) {
+			return sb.String()
+		}
+
+		p.next()
+		sb.WriteRune(c)
+	}
+}
+
+func (p *parser) parseTag() error {
+	for {
+		c, ok := p.peek()
+		if !ok || !unicode.IsSpace(c) {
+			break
+		}
+		p.next()
+	}
+
+	name := p.readSymbol()
+	if name == "" {
+		return p.errorf("missing tag name")
+	}
+
+	for {
+		c, ok := p.next()
+		if !ok {
+			return p.errorf("unterminated tag %s", name)
+		}
+		if c == '"' {
+			break
+		}
+		if !unicode.IsSpace(c) {
+			return p.errorf("malformed tag %s", name)
+		}
+	}
+
+	var value strings.Builder
+	for {
+		c, ok := p.next()
+		if !ok {
+			return p.errorf("unterminated tag %s", name)
+		}
+
+		if c == '\\' {
+			if c, ok = p.next(); !ok {
+				return p.errorf("unterminated tag %s", name)
+			}
+		} else if c == '"' {
+			break
+		}
+
+		value.WriteRune(c)
+	}
+
+	if !p.skipUntil(']') {
+		return p.errorf("unterminated tag %s", name)
+	}
+
+	// a tag after movetext starts the next game
+	if p.curr != nil && p.curr.State != nil {
+		if err := p.finishGame(); err != nil {
+			return err
+		}
+	}
+
+	p.game().Tags[name] = value.String()
+
+	return nil
+}
+
+func (p *parser) game() *Game {
+	if p.curr == nil {
+		p.curr = &Game{Tags: map[string]string{}}
+	}
+
+	return p.curr
+}
+
+// variantOptions reads the Variant tag, accepting the names other programs
+// use. a variant played from a chess960 start ends in 960, like "Atomic960"
+func variantOptions(variant string) []func(*state.FENOptions) {
+	name := strings.ToLower(strings.ReplaceAll(variant, " ", ""))
+	switch name {
+	case "chess960", "fischerandom", "fischerrandom":
+		return []func(*state.FENOptions){state.WithChess960()}
+	}
+
+	var opts []func(*state.FENOptions)
+	if rest, ok := strings.CutSuffix(name, "960"); ok {
+		opts = append(opts, state.WithChess960())
+		name = rest
+	}
+
+	if v, err := state.ParseVariant(name); err == nil {
+		opts = append(opts, state.WithVariant(v))
+	}
+
+	return opts
+}
+
+func (p *parser) startState() (*state.State, error) {
+	g := p.game()
+	if g.State != nil {
+		return g.State, nil
+	}
+
+	fen := board.StartingFEN
+	if setupFEN, ok := g.Tags["FEN"]; ok && g.Tags["SetUp"] != "0" {
+		fen = setupFEN
+	}
+
+	s, err := state.ParseFEN(fen, variantOptions(g.Tags["Variant"])...)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: game %d: %w", p.line, len(p.games)+1, err)
+	}
+
+	s.Players[piece.White] = &namedPlayer{name: g.Tags["White"]}
+	s.Players[piece.Black] = &namedPlayer{name: g.Tags["Black"]}
+	g.State = s
+
+	return g.State, nil
+}
+
+func (p *parser) parseSymbol(symbol string) error {
+	if symbol == "" {
+		c, _ := p.next()
+		return p.errorf("unexpected character %q", c)
+	}
+
+	if p.depth > 0 {
+		return nil
+	}
+
+	for _, res := range resultTokens {
+		if symbol == res {
+			if _, ok := p.game().Tags["Result"]; !ok {
+				p.game().Tags["Result"] = res
+			}
+			if _, err := p.startState(); err != nil {
+				return err
+			}
+			return p.finishGame()
+		}
+	}
+
+	san := moveNumberPattern.ReplaceAllString(symbol, "")
+	if san == "" {
+		return nil
+	}
+
+	s, err := p.startState()
+	if err != nil {
+		return err
+	}
+
+	m, err := s.ParseSAN(san)
+	if err != nil {
+		return fmt.Errorf("line %d: game %d: %w", p.line, len(p.games)+1, err)
+	}
+
+	s.MakeMove(m)
+
+	return nil
+}
+
+func (p *parser) finishGame() error {
+	if p.curr == nil {
+		return nil
+	}
+
+	if p.depth > 0 {
+		return p.errorf("unterminated variation")
+	}
+
+	if _, err := p.startState(); err != nil {
+		return err
+	}
+	p.games = append(p.games, *p.curr)
+	p.curr = nil
+
+	return nil
+}
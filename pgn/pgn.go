@@ -0,0 +1,212 @@
+package pgn
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/clock"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/ethansaxenian/chess/player"
+	"github.com/ethansaxenian/chess/state"
+)
+
+const maxLineLength = 80
+
+var sevenTagRoster = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+type Game struct {
+	Tags  map[string]string
+	State *state.State
+}
+
+func NewGame(s *state.State) Game {
+	res, over := s.CheckGameOver()
+
+	tags := map[string]string{
+		"Event":  "?",
+		"Site":   "?",
+		"Date":   time.Now().Format("2006.01.02"),
+		"Round":  "?",
+		"White":  playerTag(s.Players[piece.White]),
+		"Black":  playerTag(s.Players[piece.Black]),
+		"Result": res.PGN(),
+	}
+
+	if over {
+		tags["Termination"] = terminationTag(res.Termination)
+	}
+
+	if s.Clock != nil {
+		tags["TimeControl"] = s.Clock.TimeControl().PGN()
+	}
+
+	// chess960 games always give their start position, even the standard one
+	if fen := s.StartingFEN(); fen != board.StartingFEN || s.Chess960() {
+		tags["SetUp"] = "1"
+		tags["FEN"] = fen
+	}
+
+	switch {
+	case s.Variant() != state.Standard && s.Chess960():
+		tags["Variant"] = variantTags[s.Variant()] + "960"
+	case s.Variant() != state.Standard:
+		tags["Variant"] = variantTags[s.Variant()]
+	case s.Chess960():
+		tags["Variant"] = "Chess960"
+	}
+
+	return Game{Tags: tags, State: s}
+}
+
+// variantTags are the Variant tag values lichess writes
+var variantTags = map[state.Variant]string{
+	state.Crazyhouse:    "Crazyhouse",
+	state.ThreeCheck:    "Three-check",
+	state.KingOfTheHill: "King of the Hill",
+	state.Atomic:        "Atomic",
+	state.Antichess:     "Antichess",
+}
+
+// playerTag names a player, or is "?" for a headless state
+func playerTag(p player.Player) string {
+	if p == nil {
+		return "?"
+	}
+
+	return fmt.Sprint(p)
+}
+
+// terminationTag maps a termination onto the values the PGN standard allows
+// for the Termination tag
+func terminationTag(t state.Termination) string {
+	switch t {
+	case state.Timeout:
+		return "time forfeit"
+	case state.Abandonment:
+		return "abandoned"
+	case state.RulesInfraction:
+		return "rules infraction"
+	case state.Unterminated:
+		return "unterminated"
+	default:
+		return "normal"
+	}
+}
+
+func (g Game) Result() string {
+	if res, ok := g.Tags["Result"]; ok {
+		return res
+	}
+
+	return "*"
+}
+
+func writeTag(w io.Writer, name, value string) error {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	_, err := fmt.Fprintf(w, "[%s \"%s\"]\n", name, value)
+	return err
+}
+
+func (g Game) writeTags(w io.Writer) error {
+	for _, name := range sevenTagRoster {
+		value, ok := g.Tags[name]
+		if !ok {
+			value = "?"
+		}
+		if name == "Result" {
+			value = g.Result()
+		}
+
+		if err := writeTag(w, name, value); err != nil {
+			return err
+		}
+	}
+
+	var others []string
+	for name := range g.Tags {
+		if !slices.Contains(sevenTagRoster, name) {
+			others = append(others, name)
+		}
+	}
+	slices.Sort(others)
+
+	for _, name := range others {
+		if err := writeTag(w, name, g.Tags[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g Game) movetext() []string {
+	start := g.State.StartingPosition()
+	blackToMove := start.ActiveColor == piece.Black
+	moveNumber := start.FullmoveNumber
+
+	var clocks []time.Duration
+	if g.State.Clock != nil {
+		clocks = g.State.Clock.Readings()
+	}
+
+	var tokens []string
+	for i, san := range g.State.SANMoves() {
+		if !blackToMove {
+			tokens = append(tokens, fmt.Sprintf("%d.", moveNumber))
+		} else if i == 0 {
+			tokens = append(tokens, fmt.Sprintf("%d...", moveNumber))
+		}
+
+		tokens = append(tokens, san)
+
+		if i < len(clocks) {
+			tokens = append(tokens, fmt.Sprintf("{[%%clk %s]}", clock.Format(clocks[i])))
+		}
+
+		if blackToMove {
+			moveNumber++
+		}
+		blackToMove = !blackToMove
+	}
+
+	return append(tokens, g.Result())
+}
+
+func (g Game) Write(w io.Writer) error {
+	if err := g.writeTags(w); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+
+	var line string
+	for _, token := range g.movetext() {
+		if line != "" && len(line)+1+len(token) > maxLineLength {
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+			line = ""
+		}
+
+		if line != "" {
+			line += " "
+		}
+		line += token
+	}
+
+	_, err := fmt.Fprintf(w, "%s\n\n", line)
+	return err
+}
+
+func (g Game) String() string {
+	var sb strings.Builder
+	g.Write(&sb)
+	return sb.String()
+}
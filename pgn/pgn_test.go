@@ -0,0 +1,289 @@
+package pgn
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/clock"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/ethansaxenian/chess/player"
+	"github.com/ethansaxenian/chess/state"
+	"github.com/stretchr/testify/assert"
+)
+
+const scholarsMate = `[Event "Casual"]
+[Site "?"]
+[Date "2024.01.01"]
+[Round "?"]
+[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+
+1. e4 e5 2. Bc4 {attacking f7} Nc6 3. Qh5 Nf6?? (3... g6 4. Qf3 Nf6) 4. Qxf7# $1
+1-0
+`
+
+func TestParse(t *testing.T) {
+	games, err := Parse(strings.NewReader(scholarsMate))
+	assert.NoError(t, err)
+	assert.Len(t, games, 1)
+
+	g := games[0]
+	assert.Equal(t, "Alice", g.Tags["White"])
+	assert.Equal(t, "1-0", g.Result())
+	assert.Len(t, g.State.Moves, 7)
+	assert.Equal(t, "r1bqkb1r/pppp1Qpp/2n2n2/4p3/2B1P3/8/PPPP1PPP/RNB1K1NR b KQkq - 0 4", g.State.FEN())
+}
+
+func TestParseMultipleGames(t *testing.T) {
+	input := scholarsMate + `
+[Event "Setup"]
+[SetUp "1"]
+[FEN "4k3/P7/8/8/8/8/8/4K3 w - - 0 1"]
+[Result "*"]
+
+1. a8=N Kd7 *
+
+; a game without tags
+1. d4 d5 (1... Nf6 (1... e5 2. dxe5) 2. c4) 2. c4 *
+`
+	games, err := Parse(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Len(t, games, 3)
+
+	assert.Equal(t, "Setup", games[1].Tags["Event"])
+	assert.Equal(t, piece.Knight*piece.White, games[1].State.Piece("a8"))
+	assert.Equal(t, "N7/3k4/8/8/8/8/8/4K3 w - - 1 2", games[1].State.FEN())
+
+	assert.Equal(t, []move.Move{
+		move.NewMove("d2", "d4"),
+		move.NewMove("d7", "d5"),
+		move.NewMove("c2", "c4"),
+	}, games[2].State.Moves)
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse(strings.NewReader("1. e4 e4 *"))
+	assert.ErrorIs(t, err, state.ErrIllegalSAN)
+
+	_, err = Parse(strings.NewReader("1. e4 {unterminated"))
+	assert.ErrorIs(t, err, ErrSyntax)
+
+	_, err = Parse(strings.NewReader("1. e4 (1. d4 *"))
+	assert.ErrorIs(t, err, ErrSyntax)
+
+	var fenErr *state.FENError
+	_, err = Parse(strings.NewReader("[SetUp \"1\"]\n[FEN \"8/8/8 w - - 0 1\"]\n\n*"))
+	assert.ErrorAs(t, err, &fenErr)
+
+	// no pawn could just have moved through e3
+	_, err = Parse(strings.NewReader("[SetUp \"1\"]\n[FEN \"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e3 0 1\"]\n\n1. e3 *"))
+	if assert.ErrorAs(t, err, &fenErr) {
+		assert.Equal(t, "en passant target", fenErr.Field)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	white := player.NewHumanPlayer("Alice")
+	black := player.NewHumanPlayer("Bob")
+	s := state.StartingState(white, black)
+	s.PlayMoves([]string{"e2e4", "e7e5", "f1c4", "b8c6", "d1h5", "g8f6", "h5f7"})
+
+	g := NewGame(s)
+	g.Tags["Date"] = "2024.01.01"
+
+	expected := `[Event "?"]
+[Site "?"]
+[Date "2024.01.01"]
+[Round "?"]
+[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+[Termination "normal"]
+
+1. e4 e5 2. Bc4 Nc6 3. Qh5 Nf6 4. Qxf7# 1-0
+
+`
+	assert.Equal(t, expected, g.String())
+}
+
+func TestNewGameTermination(t *testing.T) {
+	s := state.StartingState(player.NewHumanPlayer("Alice"), player.NewHumanPlayer("Bob"))
+	g := NewGame(s)
+	assert.Equal(t, "*", g.Result())
+	assert.NotContains(t, g.Tags, "Termination")
+
+	s.EndGame(state.Win(piece.White, state.Timeout))
+	g = NewGame(s)
+	assert.Equal(t, "1-0", g.Result())
+	assert.Equal(t, "time forfeit", g.Tags["Termination"])
+}
+
+func TestNewGameWithoutPlayers(t *testing.T) {
+	s := state.StartingState(nil, nil)
+	s.PlayMoves([]string{"e2e4"})
+
+	g := NewGame(s)
+	assert.Equal(t, "?", g.Tags["White"])
+	assert.Equal(t, "?", g.Tags["Black"])
+
+	games, err := Parse(strings.NewReader(g.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, s.FEN(), games[0].State.FEN())
+}
+
+func TestRoundTrip(t *testing.T) {
+	s := state.StartingStateFromFEN("4k3/P7/8/8/8/8/8/4K3 b - - 0 1", player.NewRandoBot(), player.NewRandoBot())
+	s.PlayMoves([]string{"e8d7", "a7a8n"})
+
+	g := NewGame(s)
+	games, err := Parse(strings.NewReader(g.String()))
+	assert.NoError(t, err)
+	assert.Len(t, games, 1)
+	assert.Equal(t, s.FEN(), games[0].State.FEN())
+	assert.Equal(t, s.StartingFEN(), games[0].Tags["FEN"])
+	assert.Contains(t, g.String(), "1... Kd7 2. a8=N ")
+}
+
+func TestChess960RoundTrip(t *testing.T) {
+	s := state.StartingStateFromFEN("rk5r/pppppppp/8/8/8/8/PPPPPPPP/RK5R w KQkq - 0 1", player.NewRandoBot(), player.NewRandoBot(), state.WithChess960())
+	s.PlayMoves([]string{"b1h1", "b8a8"})
+
+	g := NewGame(s)
+	assert.Equal(t, "Chess960", g.Tags["Variant"])
+	assert.Contains(t, g.String(), "1. O-O O-O-O ")
+
+	games, err := Parse(strings.NewReader(g.String()))
+	assert.NoError(t, err)
+	assert.Len(t, games, 1)
+	assert.True(t, games[0].State.Chess960())
+	assert.Equal(t, s.FEN(), games[0].State.FEN())
+
+	// the standard start position is still written out
+	s = state.StartingStateFromFEN(board.StartingFEN, player.NewRandoBot(), player.NewRandoBot(), state.WithChess960())
+	assert.Equal(t, board.StartingFEN, NewGame(s).Tags["FEN"])
+}
+
+func TestVariant960RoundTrip(t *testing.T) {
+	tests := map[string]struct {
+		variant state.Variant
+		tag     string
+	}{
+		"crazyhouse":       {variant: state.Crazyhouse, tag: "Crazyhouse960"},
+		"king of the hill": {variant: state.KingOfTheHill, tag: "King of the Hill960"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := state.StartingStateFromFEN("rk5r/pppppppp/8/8/8/8/PPPPPPPP/RK5R w KQkq - 0 1", player.NewRandoBot(), player.NewRandoBot(), state.WithChess960(), state.WithVariant(test.variant))
+			s.PlayMoves([]string{"b1h1", "b8a8"})
+
+			g := NewGame(s)
+			assert.Equal(t, test.tag, g.Tags["Variant"])
+			assert.Contains(t, g.String(), "1. O-O O-O-O ")
+
+			games, err := Parse(strings.NewReader(g.String()))
+			assert.NoError(t, err)
+			assert.Len(t, games, 1)
+			assert.True(t, games[0].State.Chess960())
+			assert.Equal(t, test.variant, games[0].State.Variant())
+			assert.Equal(t, s.FEN(), games[0].State.FEN())
+		})
+	}
+}
+
+func TestCrazyhouseRoundTrip(t *testing.T) {
+	s := state.StartingStateFromFEN(board.StartingFEN, player.NewRandoBot(), player.NewRandoBot(), state.WithCrazyhouse())
+	s.PlayMoves([]string{"e2e4", "d7d5", "e4d5", "d8d5", "b1c3", "d5a5", "P@b4"})
+
+	g := NewGame(s)
+	assert.Equal(t, "Crazyhouse", g.Tags["Variant"])
+	assert.Contains(t, g.String(), "4. P@b4 ")
+
+	games, err := Parse(strings.NewReader(g.String()))
+	assert.NoError(t, err)
+	assert.Len(t, games, 1)
+	assert.True(t, games[0].State.Crazyhouse())
+	assert.Equal(t, s.FEN(), games[0].State.FEN())
+
+	// lichess leaves out the FEN for the usual start
+	games, err = Parse(strings.NewReader("[Variant \"Crazyhouse\"]\n\n1. e4 d5 2. exd5 Qxd5 3. P@e4 *"))
+	assert.NoError(t, err)
+	assert.Equal(t, "rnb1kbnr/ppp1pppp/8/3q4/4P3/8/PPPP1PPP/RNBQKBNR[p] b KQkq - 0 3", games[0].State.FEN())
+}
+
+func TestVariantRoundTrip(t *testing.T) {
+	tests := map[string]struct {
+		variant state.Variant
+		tag     string
+		moves   []string
+		fen     string
+	}{
+		"three-check": {
+			variant: state.ThreeCheck,
+			tag:     "Three-check",
+			moves:   []string{"e2e4", "e7e5", "f1c4", "g8f6", "c4f7"},
+			fen:     "rnbqkb1r/pppp1Bpp/5n2/4p3/4P3/8/PPPP1PPP/RNBQK1NR b KQkq - 2+3 0 3",
+		},
+		// the black king is blown up with the pawn on f7
+		"atomic": {
+			variant: state.Atomic,
+			tag:     "Atomic",
+			moves:   []string{"g1f3", "d7d5", "f3e5", "d8d6", "e5f7"},
+			fen:     "rnb4r/ppp1p1pp/3q4/3p4/8/8/PPPPPPPP/RNBQKB1R b KQ - 0 3",
+		},
+		"antichess": {
+			variant: state.Antichess,
+			tag:     "Antichess",
+			moves:   []string{"e2e4", "d7d5", "e4d5", "d8d5"},
+			fen:     "rnb1kbnr/ppp1pppp/8/3q4/8/8/PPPP1PPP/RNBQKBNR w - - 0 3",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := state.StartingStateFromFEN(board.StartingFEN, player.NewRandoBot(), player.NewRandoBot(), state.WithVariant(test.variant))
+			s.PlayMoves(test.moves)
+			assert.Equal(t, test.fen, s.FEN())
+
+			g := NewGame(s)
+			assert.Equal(t, test.tag, g.Tags["Variant"])
+
+			games, err := Parse(strings.NewReader(g.String()))
+			assert.NoError(t, err)
+			assert.Len(t, games, 1)
+			assert.Equal(t, test.variant, games[0].State.Variant())
+			assert.Equal(t, s.FEN(), games[0].State.FEN())
+		})
+	}
+}
+
+func TestThreeCheckMoveNumbers(t *testing.T) {
+	// the checks field comes before the clocks, so the move number isn't the sixth field
+	s := state.StartingStateFromFEN("4k3/8/8/8/8/8/8/R3K1N1 b - - 3+3 0 20", player.NewRandoBot(), player.NewRandoBot(), state.WithVariant(state.ThreeCheck))
+	s.PlayMoves([]string{"e8e7", "g1f3"})
+
+	assert.Contains(t, NewGame(s).String(), "20... Ke7 21. Nf3 *")
+}
+
+func TestWriteClock(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := state.StartingState(player.NewHumanPlayer("Alice"), player.NewHumanPlayer("Bob"))
+	s.StartClock(clock.New(clock.SuddenDeath(5*time.Minute, 3*time.Second), clock.WithTimeSource(func() time.Time { return now })))
+
+	for i, m := range []string{"e2e4", "e7e5", "g1f3"} {
+		now = now.Add(time.Duration(i+1) * time.Second)
+		s.PlayMoves([]string{m})
+	}
+
+	g := NewGame(s)
+	assert.Equal(t, "300+3", g.Tags["TimeControl"])
+	assert.Contains(t, g.String(), "1. e4 {[%clk 0:05:02]} e5 {[%clk 0:05:01]} 2. Nf3 {[%clk 0:05:02]} *")
+
+	games, err := Parse(strings.NewReader(g.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, s.FEN(), games[0].State.FEN())
+}
@@ -119,27 +119,35 @@ var CharToPiece = map[rune]Piece{
 	'k': King,
 }
 
-var StartingKingSquares = map[Piece]string{
-	White: "e1",
-	Black: "e8",
-}
-
-var StartingRookSquares = map[Piece]map[Side]string{
-	White: {Kingside: "h1", Queenside: "a1"},
-	Black: {Kingside: "h8", Queenside: "a8"},
-}
-
+// the king and rook end up on the same squares after castling in chess960,
+// wherever they started. see bitboard.CastlingSetup for where they start
 var CastlingSquares = map[Piece]map[Side]string{
 	White: {Kingside: "g1", Queenside: "c1"},
 	Black: {Kingside: "g8", Queenside: "c8"},
 }
 
-var CastlingIntermediateSquares = map[Piece]map[Side][]string{
-	White: {Kingside: {"f1", "g1"}, Queenside: {"d1", "c1", "b1"}},
-	Black: {Kingside: {"f8", "g8"}, Queenside: {"d8", "c8", "b8"}},
-}
-
 var RookCastlingSquares = map[Piece]map[Side]string{
 	White: {Kingside: "f1", Queenside: "d1"},
 	Black: {Kingside: "f8", Queenside: "d8"},
 }
+
+// Pocket counts the captured pieces a side can drop in crazyhouse, indexed by
+// type from Pawn to Queen
+type Pocket [King]int
+
+// PocketPieces is the order pockets are written in
+var PocketPieces = []Piece{Queen, Rook, Bishop, Knight, Pawn}
+
+func (p Pocket) Empty() bool {
+	return p == Pocket{}
+}
+
+// FEN writes the pieces in the pocket in color's case, e.g. QNPP
+func (p Pocket) FEN(color Piece) string {
+	var fen string
+	for _, t := range PocketPieces {
+		fen += strings.Repeat((t * color).FEN(), p[t])
+	}
+
+	return fen
+}
@@ -0,0 +1,96 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/polyglot"
+)
+
+// BookPlayer plays moves from an opening book, and leaves the rest of the
+// game to another player once the book has nothing for the position
+type BookPlayer struct {
+	book     *polyglot.Book
+	fallback Player
+	rand     *rand.Rand
+	best     bool
+}
+
+func NewBookPlayer(book *polyglot.Book, fallback Player, opts ...func(*BookPlayer)) *BookPlayer {
+	b := &BookPlayer{
+		book:     book,
+		fallback: fallback,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// WithBookSeed makes the weighted choice of book moves repeatable
+func WithBookSeed(seed int64) func(*BookPlayer) {
+	return func(b *BookPlayer) {
+		b.rand.Seed(seed)
+	}
+}
+
+// WithBestBookMove always plays the book move with the highest weight
+func WithBestBookMove() func(*BookPlayer) {
+	return func(b *BookPlayer) {
+		b.best = true
+	}
+}
+
+func (b *BookPlayer) GetMove(ctx context.Context, game GameView) (move.Move, error) {
+	if err := ctx.Err(); err != nil {
+		return move.Move{}, err
+	}
+
+	// the book only knows standard chess, so its moves may break the
+	// variant's rules
+	validMoves := game.ValidMoves()
+	moves := slices.DeleteFunc(b.book.Moves(game.Position()), func(m polyglot.BookMove) bool {
+		return !slices.Contains(validMoves, m.Move)
+	})
+
+	var m move.Move
+	var ok bool
+	if b.best {
+		m, ok = polyglot.Best(moves)
+	} else {
+		m, ok = polyglot.Pick(moves, b.rand)
+	}
+
+	if ok {
+		return m, nil
+	}
+
+	return b.fallback.GetMove(ctx, game)
+}
+
+func (b *BookPlayer) String() string {
+	return fmt.Sprintf("%s (book)", b.fallback)
+}
+
+func (b *BookPlayer) IsBot() bool {
+	return b.fallback.IsBot()
+}
+
+func (b *BookPlayer) SetSearchLimits(limits SearchLimits) {
+	if l, ok := b.fallback.(SearchLimiter); ok {
+		l.SetSearchLimits(limits)
+	}
+}
+
+func (b *BookPlayer) NewGame() {
+	if r, ok := b.fallback.(GameResetter); ok {
+		r.NewGame()
+	}
+}
@@ -0,0 +1,135 @@
+package player
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/ethansaxenian/chess/polyglot"
+	"github.com/stretchr/testify/assert"
+)
+
+// positionGame is a fakeGame with a real position for the book to look up
+type positionGame struct {
+	fakeGame
+	position bitboard.Position
+}
+
+func (g positionGame) Position() bitboard.Position { return g.position }
+
+func startingPosition() bitboard.Position {
+	placement, _, _ := strings.Cut(board.StartingFEN, " ")
+	castling := bitboard.WhiteKingside | bitboard.WhiteQueenside | bitboard.BlackKingside | bitboard.BlackQueenside
+	return bitboard.NewPosition(board.LoadFEN(placement), piece.White, castling, bitboard.NoSquare)
+}
+
+func bookEntry(p bitboard.Position, uci string, weight uint16) polyglot.Entry {
+	for _, m := range p.LegalMoves(nil) {
+		if m.String() == uci {
+			return polyglot.NewEntry(p, m, weight)
+		}
+	}
+
+	panic("no such move: " + uci)
+}
+
+func TestBookPlayer(t *testing.T) {
+	start := startingPosition()
+	afterE4 := start
+	afterE4.MakeMove(bitboard.Move{From: bitboard.ParseSquare("e2"), To: bitboard.ParseSquare("e4")})
+
+	book := polyglot.NewBook([]polyglot.Entry{
+		bookEntry(start, "e2e4", 1),
+		bookEntry(start, "d2d4", 9),
+	})
+	fallback := NewRandoBot(WithSeed(1))
+	fallbackGame := positionGame{
+		fakeGame: fakeGame{validMoves: []move.Move{move.NewMove("c7", "c5")}},
+		position: afterE4,
+	}
+	openings := fakeGame{validMoves: []move.Move{move.NewMove("e2", "e4"), move.NewMove("d2", "d4")}}
+
+	tests := map[string]struct {
+		opts []func(*BookPlayer)
+		game positionGame
+		want []move.Move
+	}{
+		"weighted": {
+			opts: []func(*BookPlayer){WithBookSeed(1)},
+			game: positionGame{fakeGame: openings, position: start},
+			want: []move.Move{move.NewMove("e2", "e4"), move.NewMove("d2", "d4")},
+		},
+		"best": {
+			opts: []func(*BookPlayer){WithBestBookMove()},
+			game: positionGame{fakeGame: openings, position: start},
+			want: []move.Move{move.NewMove("d2", "d4")},
+		},
+		"best the variant allows": {
+			opts: []func(*BookPlayer){WithBestBookMove()},
+			game: positionGame{fakeGame: fakeGame{validMoves: []move.Move{move.NewMove("e2", "e4")}}, position: start},
+			want: []move.Move{move.NewMove("e2", "e4")},
+		},
+		"no book move the variant allows": {
+			game: positionGame{fakeGame: fakeGame{validMoves: []move.Move{move.NewMove("c2", "c4")}}, position: start},
+			want: []move.Move{move.NewMove("c2", "c4")},
+		},
+		"out of book": {
+			game: fallbackGame,
+			want: []move.Move{move.NewMove("c7", "c5")},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			bp := NewBookPlayer(book, fallback, tc.opts...)
+
+			m, err := bp.GetMove(context.Background(), tc.game)
+			assert.NoError(t, err)
+			assert.Contains(t, tc.want, m)
+		})
+	}
+}
+
+func TestBookPlayerCancelled(t *testing.T) {
+	bp := NewBookPlayer(polyglot.NewBook(nil), NewRandoBot())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := bp.GetMove(ctx, positionGame{position: startingPosition()})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// limitedBot remembers what the book player passed on to it
+type limitedBot struct {
+	RandoBot
+	limits   SearchLimits
+	newGames int
+}
+
+func (l *limitedBot) SetSearchLimits(limits SearchLimits) {
+	l.limits = limits
+}
+
+func (l *limitedBot) NewGame() {
+	l.newGames++
+}
+
+func TestBookPlayerPassesOnLimits(t *testing.T) {
+	bot := &limitedBot{}
+	bp := NewBookPlayer(polyglot.NewBook(nil), bot)
+
+	bp.SetSearchLimits(SearchLimits{Depth: 4})
+	bp.NewGame()
+	assert.Equal(t, SearchLimits{Depth: 4}, bot.limits)
+	assert.Equal(t, 1, bot.newGames)
+
+	// players without limits are left alone
+	bp = NewBookPlayer(polyglot.NewBook(nil), NewRandoBot())
+	bp.SetSearchLimits(SearchLimits{Depth: 4})
+	bp.NewGame()
+}
@@ -2,13 +2,13 @@ package player
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"slices"
 	"strings"
 
 	"github.com/ethansaxenian/chess/move"
-	"github.com/ethansaxenian/chess/piece"
 )
 
 type HumanPlayer struct {
@@ -27,40 +27,45 @@ func getInput() string {
 	return input
 }
 
-func (h HumanPlayer) GetMove(validMoves []move.Move) move.Move {
+// getInputContext gives up waiting once ctx is done. the read itself can't be
+// interrupted, so a line typed afterwards is thrown away
+func getInputContext(ctx context.Context) (string, error) {
+	input := make(chan string, 1)
+	go func() {
+		input <- getInput()
+	}()
+
+	select {
+	case in := <-input:
+		return in, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (h HumanPlayer) GetMove(ctx context.Context, game GameView) (move.Move, error) {
+	validMoves := game.ValidMoves()
+
 	for {
-		input := getInput()
+		input, err := getInputContext(ctx)
+		if err != nil {
+			return move.Move{}, err
+		}
 
-		if len(input) != 4 {
+		m, err := move.ParseMove(input)
+		if err != nil {
 			continue
 		}
 
-		m := move.NewMove(input[:2], input[2:])
 		if slices.Contains(validMoves, m) {
-			return m
+			return m, nil
 		}
 	}
 }
 
-func (h HumanPlayer) ChoosePromotionPiece(square string) piece.Piece {
-	// TODO: promotions
-	return piece.Queen
-	// fmt.Printf("Promote %s on %s\n", piece.Pawn, square)
-	// for i, p := range piece.PossiblePromotions {
-	// 	fmt.Printf("%d: %s\n", i, p)
-	// }
-	// for {
-	// 	input := getInput()
-	//
-	// 	i, err := strconv.Atoi(input)
-	// 	if err != nil {
-	// 		continue
-	// 	}
-	//
-	// 	if i >= 0 && i < len(piece.PossiblePromotions) {
-	// 		return piece.PossiblePromotions[i]
-	// 	}
-	// }
+func (h HumanPlayer) ClaimDraw() bool {
+	fmt.Println("claim a draw? (y/n)")
+	return getInput() == "y"
 }
 
 func (h HumanPlayer) String() string {
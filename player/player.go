@@ -1,12 +1,66 @@
 package player
 
 import (
+	"context"
+	"time"
+
+	"github.com/ethansaxenian/chess/bitboard"
 	"github.com/ethansaxenian/chess/move"
 	"github.com/ethansaxenian/chess/piece"
 )
 
+// Player chooses a move when it is its turn. GetMove should return ctx.Err()
+// promptly once ctx is done, unless it already has a move it is happy to play
 type Player interface {
-	GetMove([]move.Move) move.Move
-	ChoosePromotionPiece(string) piece.Piece
+	GetMove(ctx context.Context, game GameView) (move.Move, error)
 	IsBot() bool
 }
+
+// GameView is a read-only look at the game, given to the player whose turn it is
+type GameView interface {
+	FEN() string
+	StartingFEN() string
+	MoveHistory() []move.Move
+	Turn() piece.Piece
+	ValidMoves() []move.Move
+	Piece(square string) piece.Piece
+	IsCheck() bool
+	// TimeLeft is false when the game isn't timed
+	TimeLeft(color piece.Piece) (time.Duration, bool)
+	Position() bitboard.Position
+	// Chess960 is whether castling moves are written as the king taking its rook
+	Chess960() bool
+	// Variant is the rules the game is played by, named as UCI_Variant names
+	// them: chess, crazyhouse, 3check, kingofthehill, atomic or antichess
+	Variant() string
+}
+
+// DrawClaimer players are asked whether to claim a draw by threefold repetition
+// or the fifty-move rule when one is available.
+type DrawClaimer interface {
+	ClaimDraw() bool
+}
+
+// SearchLimits bound a bot's search for its next move. zero values leave the
+// bot to its own limits
+type SearchLimits struct {
+	Depth int
+	Nodes int
+	// MoveTime is how long to think for
+	MoveTime time.Duration
+	// Infinite searches until the context is cancelled, ignoring the bot's
+	// own limits and the clock
+	Infinite bool
+}
+
+// SearchLimiter players can be told how long or how deep to search, e.g. by
+// a GUI's go command
+type SearchLimiter interface {
+	SetSearchLimits(limits SearchLimits)
+}
+
+// GameResetter players forget what they learned in the previous game when a
+// new one starts
+type GameResetter interface {
+	NewGame()
+}
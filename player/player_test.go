@@ -0,0 +1,55 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGame is just enough of a GameView for players that only look at the moves
+type fakeGame struct {
+	startingFEN string
+	moves       []move.Move
+	validMoves  []move.Move
+}
+
+func (f fakeGame) FEN() string                     { return f.startingFEN }
+func (f fakeGame) StartingFEN() string             { return f.startingFEN }
+func (f fakeGame) MoveHistory() []move.Move        { return f.moves }
+func (f fakeGame) Turn() piece.Piece               { return piece.White }
+func (f fakeGame) ValidMoves() []move.Move         { return f.validMoves }
+func (f fakeGame) Piece(square string) piece.Piece { return piece.Empty }
+func (f fakeGame) IsCheck() bool                   { return false }
+func (f fakeGame) Position() bitboard.Position     { return bitboard.Position{} }
+func (f fakeGame) Chess960() bool                  { return false }
+func (f fakeGame) Variant() string                 { return "chess" }
+
+func (f fakeGame) TimeLeft(color piece.Piece) (time.Duration, bool) { return 0, false }
+
+func TestRandoBotGetMove(t *testing.T) {
+	game := fakeGame{
+		startingFEN: board.StartingFEN,
+		validMoves:  []move.Move{move.NewMove("e2", "e4"), move.NewMove("d2", "d4")},
+	}
+
+	m, err := NewRandoBot(WithSeed(1)).GetMove(context.Background(), game)
+	assert.NoError(t, err)
+	assert.Contains(t, game.validMoves, m)
+}
+
+func TestRandoBotGetMoveCancelled(t *testing.T) {
+	game := fakeGame{validMoves: []move.Move{move.NewMove("e2", "e4")}}
+	rb := NewRandoBot(WithMoveDelay(time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := rb.GetMove(ctx, game)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
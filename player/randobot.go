@@ -1,12 +1,12 @@
 package player
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"time"
 
 	"github.com/ethansaxenian/chess/move"
-	"github.com/ethansaxenian/chess/piece"
 )
 
 type RandoBot struct {
@@ -40,17 +40,17 @@ func WithMoveDelay(delay time.Duration) func(*RandoBot) {
 	}
 }
 
-func (r RandoBot) GetMove(validMoves []move.Move) move.Move {
-	time.Sleep(r.moveDelay)
+func (r RandoBot) GetMove(ctx context.Context, game GameView) (move.Move, error) {
+	select {
+	case <-time.After(r.moveDelay):
+	case <-ctx.Done():
+		return move.Move{}, ctx.Err()
+	}
+
+	validMoves := game.ValidMoves()
 	randomIndex := r.rand.Intn(len(validMoves))
 	pick := validMoves[randomIndex]
-	return pick
-}
-
-func (r RandoBot) ChoosePromotionPiece(square string) piece.Piece {
-	randomIndex := r.rand.Intn(len(piece.PossiblePromotions))
-	pick := piece.PossiblePromotions[randomIndex]
-	return pick
+	return pick, nil
 }
 
 func (r RandoBot) String() string {
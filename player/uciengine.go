@@ -0,0 +1,299 @@
+package player
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+)
+
+const defaultEngineMoveTime = time.Second
+
+const engineQuitTimeout = 2 * time.Second
+
+type engineOption struct {
+	name, value string
+}
+
+type UCIEngine struct {
+	path    string
+	args    []string
+	options []engineOption
+
+	moveTime time.Duration
+	depth    int
+	nodes    int
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Scanner
+	name   string
+
+	startingFEN string
+	moves       []move.Move
+	chess960    bool
+	variant     string
+}
+
+func NewUCIEngine(path string, opts ...func(*UCIEngine)) (*UCIEngine, error) {
+	e := &UCIEngine{path: path, moveTime: defaultEngineMoveTime, name: path}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if err := e.start(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func WithEngineArgs(args ...string) func(*UCIEngine) {
+	return func(e *UCIEngine) {
+		e.args = args
+	}
+}
+
+func WithEngineOption(name, value string) func(*UCIEngine) {
+	return func(e *UCIEngine) {
+		e.options = append(e.options, engineOption{name, value})
+	}
+}
+
+func WithMoveTime(moveTime time.Duration) func(*UCIEngine) {
+	return func(e *UCIEngine) {
+		e.moveTime = moveTime
+	}
+}
+
+func WithSearchDepth(depth int) func(*UCIEngine) {
+	return func(e *UCIEngine) {
+		e.depth = depth
+		e.moveTime = 0
+	}
+}
+
+func WithSearchNodes(nodes int) func(*UCIEngine) {
+	return func(e *UCIEngine) {
+		e.nodes = nodes
+		e.moveTime = 0
+	}
+}
+
+func (e *UCIEngine) start() error {
+	e.cmd = exec.Command(e.path, e.args...)
+
+	stdin, err := e.cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	e.stdin = stdin
+
+	stdout, err := e.cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	e.stdout = bufio.NewScanner(stdout)
+
+	if err := e.cmd.Start(); err != nil {
+		return err
+	}
+
+	if err := e.send("uci"); err != nil {
+		return err
+	}
+
+	if _, err := e.readUntil("uciok", func(line string) {
+		if name, ok := strings.CutPrefix(line, "id name "); ok {
+			e.name = name
+		}
+	}); err != nil {
+		return err
+	}
+
+	for _, o := range e.options {
+		if err := e.send(fmt.Sprintf("setoption name %s value %s", o.name, o.value)); err != nil {
+			return err
+		}
+	}
+
+	return e.sync()
+}
+
+func (e *UCIEngine) send(cmd string) error {
+	_, err := fmt.Fprintln(e.stdin, cmd)
+	return err
+}
+
+func (e *UCIEngine) readUntil(prefix string, onLine func(string)) (string, error) {
+	for e.stdout.Scan() {
+		line := strings.TrimSpace(e.stdout.Text())
+		if strings.HasPrefix(line, prefix) {
+			return line, nil
+		}
+
+		if onLine != nil {
+			onLine(line)
+		}
+	}
+
+	if err := e.stdout.Err(); err != nil {
+		return "", err
+	}
+
+	return "", fmt.Errorf("uci engine %s: exited while waiting for %q", e.name, prefix)
+}
+
+func (e *UCIEngine) sync() error {
+	if err := e.send("isready"); err != nil {
+		return err
+	}
+
+	_, err := e.readUntil("readyok", nil)
+	return err
+}
+
+func (e *UCIEngine) positionCommand() string {
+	cmd := "position fen " + e.startingFEN
+	if e.startingFEN == "" || e.startingFEN == board.StartingFEN {
+		cmd = "position startpos"
+	}
+
+	if len(e.moves) > 0 {
+		cmd += " moves"
+		for _, m := range e.moves {
+			cmd += " " + m.String()
+		}
+	}
+
+	return cmd
+}
+
+func (e *UCIEngine) goCommand() string {
+	cmd := "go"
+
+	if e.depth > 0 {
+		cmd += fmt.Sprintf(" depth %d", e.depth)
+	}
+
+	if e.nodes > 0 {
+		cmd += fmt.Sprintf(" nodes %d", e.nodes)
+	}
+
+	if e.moveTime > 0 {
+		cmd += fmt.Sprintf(" movetime %d", e.moveTime.Milliseconds())
+	}
+
+	return cmd
+}
+
+type bestMoveResult struct {
+	move move.Move
+	err  error
+}
+
+func (e *UCIEngine) bestMove(ctx context.Context) (move.Move, error) {
+	if err := e.send(e.positionCommand()); err != nil {
+		return move.Move{}, err
+	}
+
+	if err := e.send(e.goCommand()); err != nil {
+		return move.Move{}, err
+	}
+
+	result := make(chan bestMoveResult, 1)
+	go func() {
+		m, err := e.readBestMove()
+		result <- bestMoveResult{m, err}
+	}()
+
+	select {
+	case r := <-result:
+		return r.move, r.err
+	case <-ctx.Done():
+		// the engine still answers a stop, which has to be read to stay in sync
+		e.send("stop")
+		<-result
+		return move.Move{}, ctx.Err()
+	}
+}
+
+func (e *UCIEngine) readBestMove() (move.Move, error) {
+	line, err := e.readUntil("bestmove", nil)
+	if err != nil {
+		return move.Move{}, err
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return move.Move{}, fmt.Errorf("uci engine %s: malformed bestmove: %q", e.name, line)
+	}
+
+	return move.ParseMove(fields[1])
+}
+
+func (e *UCIEngine) GetMove(ctx context.Context, game GameView) (move.Move, error) {
+	e.startingFEN = game.StartingFEN()
+	e.moves = game.MoveHistory()
+
+	// castling moves are only read and written as the king taking its rook
+	// once the engine knows the game is chess960
+	if game.Chess960() && !e.chess960 {
+		if err := e.send("setoption name UCI_Chess960 value true"); err != nil {
+			return move.Move{}, err
+		}
+		e.chess960 = true
+	}
+
+	if v := game.Variant(); v != "chess" && v != e.variant {
+		if err := e.send("setoption name UCI_Variant value " + v); err != nil {
+			return move.Move{}, err
+		}
+		e.variant = v
+	}
+
+	m, err := e.bestMove(ctx)
+	if err != nil {
+		return move.Move{}, err
+	}
+
+	if !slices.Contains(game.ValidMoves(), m) {
+		return move.Move{}, fmt.Errorf("uci engine %s played an illegal move: %s", e.name, m)
+	}
+
+	return m, nil
+}
+
+func (e *UCIEngine) Close() error {
+	e.send("quit")
+	e.stdin.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- e.cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(engineQuitTimeout):
+		e.cmd.Process.Kill()
+		return <-done
+	}
+}
+
+func (e *UCIEngine) String() string {
+	return e.name
+}
+
+func (e *UCIEngine) IsBot() bool {
+	return true
+}
@@ -0,0 +1,216 @@
+package player
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	fakeEngineEnv = "CHESS_FAKE_UCI_ENGINE"
+	fakeEngineLog = "CHESS_FAKE_UCI_ENGINE_LOG"
+)
+
+var fakeEngineMoves = map[string]string{
+	"position startpos":                               "e2e4",
+	"position startpos moves e2e4":                    "e7e5",
+	"position fen 7k/P7/8/8/8/8/8/7K w - - 0 1":       "a7a8n",
+	"position fen rk5r/8/8/8/8/8/8/RK5R w KQkq - 0 1": "b1h1",
+}
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeEngineEnv) == "1" {
+		runFakeEngine()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// runFakeEngine speaks just enough UCI to exercise UCIEngine, logging every command it receives
+func runFakeEngine() {
+	log, err := os.Create(os.Getenv(fakeEngineLog))
+	if err != nil {
+		os.Exit(1)
+	}
+	defer log.Close()
+
+	var position string
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fmt.Fprintln(log, line)
+
+		switch cmd := strings.Fields(line)[0]; cmd {
+		case "uci":
+			fmt.Println("id name FakeEngine")
+			fmt.Println("id author nobody")
+			fmt.Println("option name Hash type spin default 16 min 1 max 1024")
+			fmt.Println("uciok")
+		case "isready":
+			fmt.Println("readyok")
+		case "position":
+			position = line
+		case "go":
+			fmt.Println("info depth 1 score cp 20 pv e2e4")
+			bestMove, ok := fakeEngineMoves[position]
+			if !ok {
+				bestMove = "0000"
+			}
+			fmt.Println("bestmove", bestMove)
+		case "quit":
+			return
+		}
+	}
+}
+
+func newFakeEngine(t *testing.T, opts ...func(*UCIEngine)) (*UCIEngine, string) {
+	logPath := t.TempDir() + "/engine.log"
+	t.Setenv(fakeEngineEnv, "1")
+	t.Setenv(fakeEngineLog, logPath)
+
+	e, err := NewUCIEngine(os.Args[0], opts...)
+	assert.NoError(t, err)
+
+	return e, logPath
+}
+
+func readFakeEngineLog(t *testing.T, path string) []string {
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestUCIEngineHandshake(t *testing.T) {
+	e, logPath := newFakeEngine(t, WithEngineOption("Hash", "64"))
+	assert.Equal(t, "FakeEngine", e.String())
+	assert.True(t, e.IsBot())
+	assert.NoError(t, e.Close())
+
+	assert.Equal(t, []string{"uci", "setoption name Hash value 64", "isready", "quit"}, readFakeEngineLog(t, logPath))
+}
+
+func TestUCIEngineGetMove(t *testing.T) {
+	e, logPath := newFakeEngine(t, WithSearchDepth(4))
+
+	ctx := context.Background()
+
+	m, err := e.GetMove(ctx, fakeGame{board.StartingFEN, nil, []move.Move{move.NewMove("e2", "e4")}})
+	assert.NoError(t, err)
+	assert.Equal(t, move.NewMove("e2", "e4"), m)
+
+	m, err = e.GetMove(ctx, fakeGame{board.StartingFEN, []move.Move{move.NewMove("e2", "e4")}, []move.Move{move.NewMove("e7", "e5")}})
+	assert.NoError(t, err)
+	assert.Equal(t, move.NewMove("e7", "e5"), m)
+
+	promotion := move.NewPromotionMove("a7", "a8", piece.Knight)
+	m, err = e.GetMove(ctx, fakeGame{"7k/P7/8/8/8/8/8/7K w - - 0 1", nil, []move.Move{promotion}})
+	assert.NoError(t, err)
+	assert.Equal(t, promotion, m)
+
+	// the fake engine answers 0000 to anything it doesn't know
+	_, err = e.GetMove(ctx, fakeGame{"7k/8/8/8/8/8/8/R6K w - - 0 1", nil, []move.Move{move.NewMove("a1", "a8")}})
+	assert.Error(t, err)
+
+	assert.NoError(t, e.Close())
+
+	assert.Equal(t, []string{
+		"uci",
+		"isready",
+		"position startpos",
+		"go depth 4",
+		"position startpos moves e2e4",
+		"go depth 4",
+		"position fen 7k/P7/8/8/8/8/8/7K w - - 0 1",
+		"go depth 4",
+		"position fen 7k/8/8/8/8/8/8/R6K w - - 0 1",
+		"go depth 4",
+		"quit",
+	}, readFakeEngineLog(t, logPath))
+}
+
+type chess960Game struct {
+	fakeGame
+}
+
+func (chess960Game) Chess960() bool { return true }
+
+func TestUCIEngineChess960(t *testing.T) {
+	e, logPath := newFakeEngine(t, WithSearchDepth(4))
+
+	castle := move.NewMove("b1", "h1")
+	game := chess960Game{fakeGame{"rk5r/8/8/8/8/8/8/RK5R w KQkq - 0 1", nil, []move.Move{castle}}}
+	for range 2 {
+		m, err := e.GetMove(context.Background(), game)
+		assert.NoError(t, err)
+		assert.Equal(t, castle, m)
+	}
+
+	assert.NoError(t, e.Close())
+
+	assert.Equal(t, []string{
+		"uci",
+		"isready",
+		"setoption name UCI_Chess960 value true",
+		"position fen rk5r/8/8/8/8/8/8/RK5R w KQkq - 0 1",
+		"go depth 4",
+		"position fen rk5r/8/8/8/8/8/8/RK5R w KQkq - 0 1",
+		"go depth 4",
+		"quit",
+	}, readFakeEngineLog(t, logPath))
+}
+
+type atomicGame struct {
+	fakeGame
+}
+
+func (atomicGame) Variant() string { return "atomic" }
+
+func TestUCIEngineVariant(t *testing.T) {
+	e, logPath := newFakeEngine(t, WithSearchDepth(4))
+
+	promotion := move.NewPromotionMove("a7", "a8", piece.Knight)
+	game := atomicGame{fakeGame{"7k/P7/8/8/8/8/8/7K w - - 0 1", nil, []move.Move{promotion}}}
+	for range 2 {
+		m, err := e.GetMove(context.Background(), game)
+		assert.NoError(t, err)
+		assert.Equal(t, promotion, m)
+	}
+
+	assert.NoError(t, e.Close())
+
+	assert.Equal(t, []string{
+		"uci",
+		"isready",
+		"setoption name UCI_Variant value atomic",
+		"position fen 7k/P7/8/8/8/8/8/7K w - - 0 1",
+		"go depth 4",
+		"position fen 7k/P7/8/8/8/8/8/7K w - - 0 1",
+		"go depth 4",
+		"quit",
+	}, readFakeEngineLog(t, logPath))
+}
+
+func TestUCIEngineGoCommand(t *testing.T) {
+	e := &UCIEngine{moveTime: 1500 * time.Millisecond}
+	assert.Equal(t, "go movetime 1500", e.goCommand())
+
+	e = &UCIEngine{}
+	WithSearchNodes(10000)(e)
+	assert.Equal(t, "go nodes 10000", e.goCommand())
+}
+
+func TestUCIEngineMissingBinary(t *testing.T) {
+	_, err := NewUCIEngine(t.TempDir() + "/does-not-exist")
+	assert.Error(t, err)
+}
@@ -0,0 +1,257 @@
+// Package polyglot reads and writes opening books in the polyglot .bin format:
+// a list of 16 byte entries sorted by the zobrist key of the position they
+// are for, each holding a move, a weight and a learn value, all big endian.
+//
+// positions are keyed with bitboard.Position.Hash, which uses polyglot's
+// Random64 numbers and rules (e.g. en passant only counts when a capture is
+// possible), so books written by other tools work too
+package polyglot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/ethansaxenian/chess/assert"
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+)
+
+const entrySize = 16
+
+var ErrTruncated = errors.New("polyglot: truncated entry")
+
+type Entry struct {
+	Key    uint64
+	Move   uint16
+	Weight uint16
+	Learn  uint32
+}
+
+// BookMove is a legal move from the book with its weight
+type BookMove struct {
+	Move   move.Move
+	Weight uint16
+}
+
+type Book struct {
+	entries []Entry
+}
+
+func Key(p bitboard.Position) uint64 {
+	return p.Hash()
+}
+
+// NewEntry is the entry for playing m in p
+func NewEntry(p bitboard.Position, m bitboard.Move, weight uint16) Entry {
+	return Entry{Key: Key(p), Move: EncodeMove(p, m), Weight: weight}
+}
+
+// NewBook sorts the entries by key, keeping the order of entries with the same one
+func NewBook(entries []Entry) *Book {
+	entries = slices.Clone(entries)
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		switch {
+		case a.Key < b.Key:
+			return -1
+		case a.Key > b.Key:
+			return 1
+		}
+		return 0
+	})
+
+	return &Book{entries}
+}
+
+func Read(r io.Reader) (*Book, error) {
+	var entries []Entry
+	var buf [entrySize]byte
+
+	for {
+		_, err := io.ReadFull(r, buf[:])
+		if err == io.EOF {
+			break
+		}
+		if err == io.ErrUnexpectedEOF {
+			return nil, ErrTruncated
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, Entry{
+			Key:    binary.BigEndian.Uint64(buf[0:8]),
+			Move:   binary.BigEndian.Uint16(buf[8:10]),
+			Weight: binary.BigEndian.Uint16(buf[10:12]),
+			Learn:  binary.BigEndian.Uint32(buf[12:16]),
+		})
+	}
+
+	// books should already be sorted, but binary search depends on it
+	return NewBook(entries), nil
+}
+
+func Open(path string) (*Book, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b, err := Read(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return b, nil
+}
+
+// Write stores the book's entries in order
+func (b *Book) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	var buf [entrySize]byte
+	for _, e := range b.entries {
+		binary.BigEndian.PutUint64(buf[0:8], e.Key)
+		binary.BigEndian.PutUint16(buf[8:10], e.Move)
+		binary.BigEndian.PutUint16(buf[10:12], e.Weight)
+		binary.BigEndian.PutUint32(buf[12:16], e.Learn)
+
+		if _, err := bw.Write(buf[:]); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+func (b *Book) Len() int {
+	return len(b.entries)
+}
+
+// Lookup returns the entries for a key
+func (b *Book) Lookup(key uint64) []Entry {
+	i := sort.Search(len(b.entries), func(i int) bool { return b.entries[i].Key >= key })
+
+	j := i
+	for j < len(b.entries) && b.entries[j].Key == key {
+		j++
+	}
+
+	return b.entries[i:j]
+}
+
+// Moves are the book's legal moves in p, in the book's order. entries that
+// don't decode to a legal move, e.g. from a key collision, are skipped
+func (b *Book) Moves(p bitboard.Position) []BookMove {
+	var moves []BookMove
+
+	legalMoves := p.LegalMoves(make([]bitboard.Move, 0, 64))
+	for _, e := range b.Lookup(Key(p)) {
+		if m, ok := decodeMove(p, e.Move, legalMoves); ok {
+			moves = append(moves, BookMove{m.ToMove(), e.Weight})
+		}
+	}
+
+	return moves
+}
+
+// Best is the book move with the highest weight, the first one on ties
+func (b *Book) Best(p bitboard.Position) (move.Move, bool) {
+	return Best(b.Moves(p))
+}
+
+// Best is the move with the highest weight, the first one on ties
+func Best(moves []BookMove) (move.Move, bool) {
+	if len(moves) == 0 {
+		return move.Move{}, false
+	}
+
+	best := moves[0]
+	for _, m := range moves[1:] {
+		if m.Weight > best.Weight {
+			best = m
+		}
+	}
+
+	return best.Move, true
+}
+
+// Pick chooses a book move at random, in proportion to the weights. moves with
+// no weight are only played if every move has none
+func (b *Book) Pick(p bitboard.Position, r *rand.Rand) (move.Move, bool) {
+	return Pick(b.Moves(p), r)
+}
+
+// Pick chooses one of moves at random, in proportion to the weights
+func Pick(moves []BookMove, r *rand.Rand) (move.Move, bool) {
+	if len(moves) == 0 {
+		return move.Move{}, false
+	}
+
+	total := 0
+	for _, m := range moves {
+		total += int(m.Weight)
+	}
+
+	if total == 0 {
+		return moves[r.Intn(len(moves))].Move, true
+	}
+
+	n := r.Intn(total)
+	for _, m := range moves {
+		n -= int(m.Weight)
+		if n < 0 {
+			return m.Move, true
+		}
+	}
+
+	assert.Raise("polyglot: weighted pick ran past the total")
+	return move.Move{}, false
+}
+
+// EncodeMove packs a move in p into polyglot's 16 bits: the target square,
+// the source square and the promotion piece, 3 bits for knight to queen.
+// castling is encoded as the king taking its own rook
+func EncodeMove(p bitboard.Position, m bitboard.Move) uint16 {
+	to := m.To
+	if m.IsCastle() {
+		to = p.CastlingRook(m)
+	}
+
+	var promotion int
+	if m.Promotion != piece.Empty {
+		promotion = int(m.Promotion.Type() - piece.Pawn)
+	}
+
+	return uint16(to | m.From<<6 | promotion<<12)
+}
+
+func decodeMove(p bitboard.Position, raw uint16, legalMoves []bitboard.Move) (bitboard.Move, bool) {
+	to := int(raw & 0x3f)
+	from := int(raw >> 6 & 0x3f)
+
+	var promotion piece.Piece
+	if n := raw >> 12 & 0x7; n != 0 {
+		promotion = piece.Piece(n) + piece.Pawn
+	}
+
+	for _, m := range legalMoves {
+		if m.From != from || m.Promotion.Type() != promotion {
+			continue
+		}
+
+		if m.To == to || m.IsCastle() && p.CastlingRook(m) == to {
+			return m, true
+		}
+	}
+
+	return bitboard.Move{}, false
+}
@@ -0,0 +1,250 @@
+package polyglot
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+// position only reads the parts of a FEN a book key depends on
+func position(fen string) bitboard.Position {
+	fields := strings.Fields(fen)
+
+	activeColor := piece.White
+	if fields[1] == "b" {
+		activeColor = piece.Black
+	}
+
+	var castling uint8
+	for _, c := range fields[2] {
+		switch c {
+		case 'K':
+			castling |= bitboard.WhiteKingside
+		case 'Q':
+			castling |= bitboard.WhiteQueenside
+		case 'k':
+			castling |= bitboard.BlackKingside
+		case 'q':
+			castling |= bitboard.BlackQueenside
+		}
+	}
+
+	return bitboard.NewPosition(board.LoadFEN(fields[0]), activeColor, castling, bitboard.ParseSquare(fields[3]))
+}
+
+func findMove(p bitboard.Position, uci string) bitboard.Move {
+	for _, m := range p.LegalMoves(nil) {
+		if m.String() == uci {
+			return m
+		}
+	}
+
+	panic("no such move: " + uci)
+}
+
+// newTestBook has weighted replies to 1. e4 and 1. d4 from the starting position
+func newTestBook() *Book {
+	start := position(board.StartingFEN)
+
+	afterE4 := start
+	afterE4.MakeMove(findMove(start, "e2e4"))
+
+	return NewBook([]Entry{
+		NewEntry(afterE4, findMove(afterE4, "c7c5"), 30),
+		NewEntry(start, findMove(start, "e2e4"), 3),
+		NewEntry(afterE4, findMove(afterE4, "e7e5"), 10),
+		NewEntry(start, findMove(start, "d2d4"), 1),
+	})
+}
+
+func TestEncodeMove(t *testing.T) {
+	tests := map[string]struct {
+		fen  string
+		move string
+		want uint16
+	}{
+		"quiet": {
+			fen:  board.StartingFEN,
+			move: "e2e4",
+			want: 12<<6 | 28,
+		},
+		"white kingside castle takes the rook": {
+			fen:  "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			move: "e1g1",
+			want: 4<<6 | 7,
+		},
+		"black queenside castle takes the rook": {
+			fen:  "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1",
+			move: "e8c8",
+			want: 60<<6 | 56,
+		},
+		"promotion": {
+			fen:  "8/P6k/8/8/8/8/8/K7 w - - 0 1",
+			move: "a7a8q",
+			want: 4<<12 | 48<<6 | 56,
+		},
+		"underpromotion": {
+			fen:  "8/P6k/8/8/8/8/8/K7 w - - 0 1",
+			move: "a7a8n",
+			want: 1<<12 | 48<<6 | 56,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := position(tc.fen)
+			m := findMove(p, tc.move)
+
+			assert.Equal(t, tc.want, EncodeMove(p, m))
+
+			decoded, ok := decodeMove(p, tc.want, p.LegalMoves(nil))
+			assert.True(t, ok)
+			assert.Equal(t, m, decoded)
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, uint64(0x463b96181691fc9c), Key(position(board.StartingFEN)))
+	assert.Equal(t, uint64(0x22a48b5a8e47ff78), Key(position("rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3")))
+}
+
+// a book written by another tool, holding 1. e4 for the starting position
+func TestReadForeignBook(t *testing.T) {
+	entry := []byte{0x46, 0x3b, 0x96, 0x18, 0x16, 0x91, 0xfc, 0x9c, 0x03, 0x1c, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}
+
+	book, err := Read(bytes.NewReader(entry))
+	assert.NoError(t, err)
+	assert.Equal(t, []BookMove{{move.NewMove("e2", "e4"), 1}}, book.Moves(position(board.StartingFEN)))
+}
+
+func TestReadWrite(t *testing.T) {
+	book := newTestBook()
+
+	var buf bytes.Buffer
+	assert.NoError(t, book.Write(&buf))
+	assert.Equal(t, 4*entrySize, buf.Len())
+
+	read, err := Read(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, book.entries, read.entries)
+}
+
+func TestReadTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, newTestBook().Write(&buf))
+
+	_, err := Read(bytes.NewReader(buf.Bytes()[:buf.Len()-3]))
+	assert.ErrorIs(t, err, ErrTruncated)
+}
+
+func TestReadSorts(t *testing.T) {
+	entries := []Entry{{Key: 3}, {Key: 1, Weight: 1}, {Key: 2}, {Key: 1, Weight: 2}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, (&Book{entries}).Write(&buf))
+
+	book, err := Read(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{{Key: 1, Weight: 1}, {Key: 1, Weight: 2}}, book.Lookup(1))
+	assert.Empty(t, book.Lookup(4))
+}
+
+func TestMoves(t *testing.T) {
+	book := newTestBook()
+	start := position(board.StartingFEN)
+
+	assert.Equal(t, []BookMove{
+		{move.NewMove("e2", "e4"), 3},
+		{move.NewMove("d2", "d4"), 1},
+	}, book.Moves(start))
+
+	// 1. e4 set up from a FEN, where the en passant square doesn't change the key
+	p := position("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
+	assert.Equal(t, []BookMove{
+		{move.NewMove("c7", "c5"), 30},
+		{move.NewMove("e7", "e5"), 10},
+	}, book.Moves(p))
+
+	assert.Empty(t, book.Moves(position("rnbqkbnr/pppppppp/8/8/3P4/8/PPP1PPPP/RNBQKBNR b KQkq - 0 1")))
+}
+
+func TestMovesSkipsIllegalEntries(t *testing.T) {
+	start := position(board.StartingFEN)
+	book := NewBook([]Entry{
+		{Key: Key(start), Move: 4<<6 | 36, Weight: 5}, // e1e5
+		NewEntry(start, findMove(start, "g1f3"), 1),
+	})
+
+	assert.Equal(t, []BookMove{{move.NewMove("g1", "f3"), 1}}, book.Moves(start))
+}
+
+func TestBest(t *testing.T) {
+	book := newTestBook()
+
+	m, ok := book.Best(position(board.StartingFEN))
+	assert.True(t, ok)
+	assert.Equal(t, move.NewMove("e2", "e4"), m)
+
+	_, ok = book.Best(position("8/8/8/8/8/8/k7/K7 w - - 0 1"))
+	assert.False(t, ok)
+}
+
+func TestPick(t *testing.T) {
+	book := newTestBook()
+	start := position(board.StartingFEN)
+	r := rand.New(rand.NewSource(1))
+
+	counts := map[move.Move]int{}
+	for i := 0; i < 4000; i++ {
+		m, ok := book.Pick(start, r)
+		assert.True(t, ok)
+		counts[m]++
+	}
+
+	assert.Len(t, counts, 2)
+	assert.InDelta(t, 3000, counts[move.NewMove("e2", "e4")], 150)
+	assert.InDelta(t, 1000, counts[move.NewMove("d2", "d4")], 150)
+}
+
+func TestPickWithoutWeights(t *testing.T) {
+	start := position(board.StartingFEN)
+	book := NewBook([]Entry{NewEntry(start, findMove(start, "c2c4"), 0)})
+
+	m, ok := book.Pick(start, rand.New(rand.NewSource(1)))
+	assert.True(t, ok)
+	assert.Equal(t, move.NewMove("c2", "c4"), m)
+}
+
+func TestEncodeChess960Castle(t *testing.T) {
+	// king on b1 with rooks on a1 and h1
+	setup := bitboard.NewCastlingSetup(
+		bitboard.CastlingSquares{King: 1, KingsideRook: 7, QueensideRook: 0},
+		bitboard.CastlingSquares{King: 57, KingsideRook: 63, QueensideRook: 56},
+		true,
+	)
+	p := bitboard.NewPosition(
+		board.LoadFEN("rk5r/8/8/8/8/8/8/RK5R"),
+		piece.White,
+		bitboard.WhiteKingside|bitboard.WhiteQueenside,
+		bitboard.NoSquare,
+		bitboard.WithCastlingSetup(setup),
+	)
+
+	for uci, want := range map[string]uint16{"b1h1": 1<<6 | 7, "b1a1": 1<<6 | 0} {
+		m := findMove(p, uci)
+		assert.True(t, m.IsCastle())
+		assert.Equal(t, want, EncodeMove(p, m))
+
+		decoded, ok := decodeMove(p, want, p.LegalMoves(nil))
+		assert.True(t, ok)
+		assert.Equal(t, m, decoded)
+	}
+}
@@ -0,0 +1,72 @@
+package state
+
+import (
+	"slices"
+
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+)
+
+// antichess is won by losing every piece, or by having no moves. captures
+// are compulsory, there is no check or castling, and the king is an ordinary
+// piece that can be captured and promoted to
+type antichess struct{}
+
+func (antichess) String() string {
+	return "antichess"
+}
+
+func (antichess) LegalMoves(s *State) []move.Move {
+	pos := s.Position()
+
+	var moves, captures []move.Move
+	for _, m := range pos.PseudoLegalMoves(make([]bitboard.Move, 0, 64)) {
+		if m.IsCastle() {
+			continue
+		}
+
+		mv := m.ToMove()
+		if m.IsCapture() {
+			captures = append(captures, mv)
+		} else {
+			moves = append(moves, mv)
+		}
+
+		// pawns can promote to a king too
+		if m.Promotion == piece.Queen {
+			mv.Promotion = piece.King
+			if m.IsCapture() {
+				captures = append(captures, mv)
+			} else {
+				moves = append(moves, mv)
+			}
+		}
+	}
+
+	if len(captures) > 0 {
+		moves = captures
+	}
+	move.SortMoves(moves)
+
+	return moves
+}
+
+func (antichess) AfterMove(*State, move.Move, bool) {}
+
+// only the side to move can just have lost its last piece
+func (antichess) Result(s *State, moves []move.Move) (GameResult, bool) {
+	if !slices.ContainsFunc(s.Board[:], func(p piece.Piece) bool { return p.Color() == s.ActiveColor }) {
+		return Win(s.ActiveColor, AllPiecesLost), true
+	}
+
+	if len(moves) == 0 {
+		return Win(s.ActiveColor, Stalemate), true
+	}
+
+	return GameResult{}, false
+}
+
+func (antichess) Drawn(*State) (GameResult, bool) {
+	return GameResult{}, false
+}
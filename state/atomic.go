@@ -0,0 +1,127 @@
+package state
+
+import (
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+)
+
+// atomic captures explode, taking the capturing piece and every piece but a
+// pawn next to the capture square off the board. blowing up the other king
+// wins, so kings can't capture and a king next to the other one is never in
+// check
+type atomic struct{}
+
+func (atomic) String() string {
+	return "atomic"
+}
+
+func (atomic) LegalMoves(s *State) []move.Move {
+	pos := s.Position()
+
+	var moves []move.Move
+	for _, m := range pos.PseudoLegalMoves(make([]bitboard.Move, 0, 64)) {
+		if m.IsCapture() && pos.Piece(m.From).Type() == piece.King {
+			continue
+		}
+
+		if atomicLegal(pos, m) {
+			moves = append(moves, m.ToMove())
+		}
+	}
+	move.SortMoves(moves)
+
+	return moves
+}
+
+// atomicLegal plays m on a copy of pos, and checks the mover's king survives
+// the explosion and isn't left in check, unless the other king didn't survive
+func atomicLegal(pos bitboard.Position, m bitboard.Move) bool {
+	us := pos.SideToMove()
+
+	next := pos
+	next.MakeMove(m)
+
+	var squares board.Chessboard
+	for sq := range squares {
+		squares[sq] = next.Piece(sq)
+	}
+	if m.IsCapture() {
+		for _, sq := range explosion(squares, m.To) {
+			squares[sq] = piece.Empty
+		}
+	}
+
+	after := bitboard.NewPosition(squares, us, 0, bitboard.NoSquare)
+	switch {
+	case after.Pieces(us, piece.King) == 0:
+		return false
+	case after.Pieces(us*-1, piece.King) == 0:
+		return true
+	default:
+		return !atomicInCheck(after)
+	}
+}
+
+// explosion is the squares a capture on target clears: target itself, where
+// the capturing piece now stands, and the pieces around it that aren't pawns
+func explosion(squares board.Chessboard, target int) []int {
+	cleared := []int{target}
+
+	around := bitboard.KingAttacks(target)
+	for around != 0 {
+		sq := around.PopLSB()
+		if p := squares[sq]; p != piece.Empty && p.Type() != piece.Pawn {
+			cleared = append(cleared, sq)
+		}
+	}
+
+	return cleared
+}
+
+// atomicInCheck is whether the side to move's king is attacked, which it
+// can't be while the kings touch
+func atomicInCheck(pos bitboard.Position) bool {
+	us := pos.SideToMove()
+	ours, theirs := pos.Pieces(us, piece.King), pos.Pieces(us*-1, piece.King)
+	if ours == 0 || theirs == 0 || bitboard.KingAttacks(ours.LSB())&theirs != 0 {
+		return false
+	}
+
+	return pos.InCheck()
+}
+
+func (atomic) AfterMove(s *State, m move.Move, captured bool) {
+	if !captured {
+		return
+	}
+
+	for _, sq := range explosion(s.nextBoard, board.SquareToIndex(m.Target)) {
+		s.removePiece(bitboard.SquareName(sq))
+	}
+}
+
+func (atomic) Result(s *State, moves []move.Move) (GameResult, bool) {
+	pos := s.Position()
+	for _, color := range piece.AllColors {
+		if pos.Pieces(color, piece.King) == 0 {
+			return Win(color*-1, Explosion), true
+		}
+	}
+
+	if len(moves) > 0 {
+		return GameResult{}, false
+	}
+
+	if atomicInCheck(pos) {
+		return Win(s.ActiveColor*-1, Checkmate), true
+	}
+
+	return Draw(Stalemate), true
+}
+
+// kings can't capture, so they can't blow each other up either
+func (atomic) Drawn(s *State) (GameResult, bool) {
+	return bareKings(s)
+}
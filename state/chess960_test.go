@@ -0,0 +1,210 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+// reference counts from https://www.chessprogramming.org/Chess960_Perft_Results
+var chess960PerftPositions = map[string]struct {
+	fen   string
+	nodes []int
+}{
+	"position 1": {
+		fen:   "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+		nodes: []int{21, 528, 12189},
+	},
+	"position 3": {
+		fen:   "b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRKRB w GE - 1 9",
+		nodes: []int{20, 479, 10471},
+	},
+}
+
+func TestChess960Perft(t *testing.T) {
+	for name, test := range chess960PerftPositions {
+		for depth := 1; depth <= len(test.nodes); depth++ {
+			t.Run(fmt.Sprintf("%s depth %d", name, depth), func(t *testing.T) {
+				s := NewTestStateFromFEN(test.fen, WithChess960())
+				assert.Equal(t, test.nodes[depth-1], s.Perft(depth))
+				assert.Equal(t, test.fen, s.ShredderFEN())
+			})
+		}
+	}
+}
+
+func TestChess960MatchesLegacyGenerator(t *testing.T) {
+	fens := []string{
+		"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+		// the king castles onto its rook's square, or doesn't move at all
+		"1r4kr/8/8/8/8/8/8/1R4KR w BHbh - 0 1",
+		// the rook shields the king from the queen until it castles
+		"4k3/8/8/8/8/8/8/qRK4R w BH - 0 1",
+	}
+
+	for _, fen := range fens {
+		s := NewTestStateFromFEN(fen, WithChess960())
+		assert.Equal(t, generateLegalMovesByMakeUndo(*s), s.GeneratePossibleMoves(), fen)
+		assert.Equal(t, legacyPerft(s, 2), s.Perft(2), fen)
+	}
+}
+
+func TestChess960Castling(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		castle   move.Move
+		legal    bool
+		expected string
+	}{
+		"kingside": {
+			fen:      "rk5r/8/8/8/8/8/8/RK5R w HAha - 0 1",
+			castle:   move.NewMove("b1", "h1"),
+			legal:    true,
+			expected: "rk5r/8/8/8/8/8/8/R4RK1 b kq - 1 1",
+		},
+		"queenside": {
+			fen:      "rk5r/8/8/8/8/8/8/RK5R w HAha - 0 1",
+			castle:   move.NewMove("b1", "a1"),
+			legal:    true,
+			expected: "rk5r/8/8/8/8/8/8/2KR3R b kq - 1 1",
+		},
+		"king already on its square": {
+			fen:      "6kr/8/8/8/8/8/8/6KR b Hh - 0 1",
+			castle:   move.NewMove("g8", "h8"),
+			legal:    true,
+			expected: "5rk1/8/8/8/8/8/8/6KR w K - 1 2",
+		},
+		"rook already on its square": {
+			fen:      "4k3/8/8/8/8/8/8/3RK3 w D - 0 1",
+			castle:   move.NewMove("e1", "d1"),
+			legal:    true,
+			expected: "4k3/8/8/8/8/8/8/2KR4 b - - 1 1",
+		},
+		"king and rook swap": {
+			fen:      "4k3/8/8/8/8/8/8/5RK1 w F - 0 1",
+			castle:   move.NewMove("g1", "f1"),
+			legal:    true,
+			expected: "4k3/8/8/8/8/8/8/2KR4 b - - 1 1",
+		},
+		"blocked by a piece on the rook's path": {
+			fen:    "4k3/8/8/8/8/8/8/RN1K4 w A - 0 1",
+			castle: move.NewMove("d1", "a1"),
+		},
+		"king passes through check": {
+			fen:    "4k3/8/8/8/8/8/5r2/1K5R w H - 0 1",
+			castle: move.NewMove("b1", "h1"),
+		},
+		"rook was shielding the king": {
+			fen:    "4k3/8/8/8/8/8/8/qRK4R w B - 0 1",
+			castle: move.NewMove("c1", "b1"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewTestStateFromFEN(test.fen, WithChess960())
+
+			if !test.legal {
+				assert.NotContains(t, s.GeneratePossibleMoves(), test.castle)
+				return
+			}
+
+			assert.Contains(t, s.GeneratePossibleMoves(), test.castle)
+
+			hash := s.Hash()
+			s.MakeMove(test.castle)
+			assert.Equal(t, test.expected, s.FEN())
+			assert.Equal(t, s.Position().Hash(), s.Hash())
+
+			s.Undo()
+			assert.Equal(t, test.fen, s.ShredderFEN())
+			assert.Equal(t, hash, s.Hash())
+		})
+	}
+}
+
+func TestChess960FENCastlingRights(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		xfen     string
+		shredder string
+	}{
+		"outermost rooks": {
+			fen:      "rk5r/8/8/8/8/8/8/RK5R w KQkq - 0 1",
+			xfen:     "rk5r/8/8/8/8/8/8/RK5R w KQkq - 0 1",
+			shredder: "rk5r/8/8/8/8/8/8/RK5R w HAha - 0 1",
+		},
+		"shredder": {
+			fen:      "rk5r/8/8/8/8/8/8/RK5R w HAha - 0 1",
+			xfen:     "rk5r/8/8/8/8/8/8/RK5R w KQkq - 0 1",
+			shredder: "rk5r/8/8/8/8/8/8/RK5R w HAha - 0 1",
+		},
+		"inner rook needs its file in x-fen": {
+			fen:      "1k2r2r/8/8/8/8/8/8/1K2R2R w Ee - 0 1",
+			xfen:     "1k2r2r/8/8/8/8/8/8/1K2R2R w Ee - 0 1",
+			shredder: "1k2r2r/8/8/8/8/8/8/1K2R2R w Ee - 0 1",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := ParseFEN(test.fen, WithChess960(), WithLegalPosition())
+			assert.NoError(t, err)
+			assert.True(t, s.Chess960())
+			assert.Equal(t, test.xfen, s.FEN())
+			assert.Equal(t, test.shredder, s.ShredderFEN())
+		})
+	}
+}
+
+func TestChess960FENErrors(t *testing.T) {
+	tests := map[string]string{
+		"no rook on the file":      "rk5r/8/8/8/8/8/8/RK5R w G - 0 1",
+		"no rook outside the king": "1k5r/8/8/8/8/8/8/1K5R w Q - 0 1",
+		"king off the back rank":   "7r/8/8/8/8/8/1K6/7R w H - 0 1",
+	}
+
+	for name, fen := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseFEN(fen, WithChess960())
+			var fenErr *FENError
+			assert.ErrorAs(t, err, &fenErr)
+			assert.Equal(t, "castling rights", fenErr.Field)
+		})
+	}
+
+	// file letters are only read in chess960
+	_, err := ParseFEN("rk5r/8/8/8/8/8/8/RK5R w HAha - 0 1")
+	assert.Error(t, err)
+}
+
+func TestChess960StartPositions(t *testing.T) {
+	for _, n := range []int{0, 518, 959} {
+		fen, err := board.Chess960FEN(n)
+		assert.NoError(t, err)
+
+		s := NewTestStateFromFEN(fen, WithChess960())
+		assert.Equal(t, 20, len(s.GeneratePossibleMoves()))
+		assert.Equal(t, fen, s.FEN())
+		assert.Equal(t, piece.King, s.Piece(bitboard.SquareName(s.castlingSetup().King(piece.White))))
+	}
+}
+
+func TestChess960SAN(t *testing.T) {
+	s := NewTestStateFromFEN("rk5r/8/8/8/8/8/8/RK5R w HAha - 0 1", WithChess960())
+
+	assert.Equal(t, "O-O", s.SAN(move.NewMove("b1", "h1")))
+	assert.Equal(t, "O-O-O", s.SAN(move.NewMove("b1", "a1")))
+
+	m, err := s.ParseSAN("O-O-O")
+	assert.NoError(t, err)
+	assert.Equal(t, move.NewMove("b1", "a1"), m)
+
+	s.MakeMove(m)
+	assert.Equal(t, []string{"O-O-O"}, s.SANMoves())
+}
@@ -0,0 +1,156 @@
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/clock"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTime struct {
+	now time.Time
+}
+
+func (f *fakeTime) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeTime) advance(d time.Duration) {
+	f.now = f.now.Add(d)
+}
+
+func newTimedTestState(t *testing.T, fen, tc string) (*State, *fakeTime) {
+	control, err := clock.ParseTimeControl(tc)
+	assert.NoError(t, err)
+
+	ft := &fakeTime{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := NewTestStateFromFEN(fen)
+	s.StartClock(clock.New(control, clock.WithTimeSource(ft.Now)))
+
+	return s, ft
+}
+
+func TestMakeMovePressesClock(t *testing.T) {
+	s, ft := newTimedTestState(t, board.StartingFEN, "1+1")
+
+	ft.advance(10 * time.Second)
+	s.PlayMoves([]string{"e2e4"})
+	ft.advance(20 * time.Second)
+
+	assert.Equal(t, 51*time.Second, s.Clock.Remaining(piece.White))
+	assert.Equal(t, 40*time.Second, s.Clock.Remaining(piece.Black))
+
+	left, ok := s.View().TimeLeft(piece.Black)
+	assert.True(t, ok)
+	assert.Equal(t, 40*time.Second, left)
+
+	// trying moves out doesn't touch the clock
+	s.SAN(s.GeneratePossibleMoves()[0])
+	s.GeneratePossibleMoves()
+	assert.Len(t, s.Clock.Readings(), 1)
+
+	s.Undo()
+	assert.Empty(t, s.Clock.Readings())
+	assert.Equal(t, 50*time.Second, s.Clock.Remaining(piece.White))
+}
+
+func TestCheckGameOverTimeout(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		expected GameResult
+	}{
+		"opponent can mate": {
+			fen:      board.StartingFEN,
+			expected: Win(piece.Black, Timeout),
+		},
+		"opponent has a rook": {
+			fen:      "4k3/8/8/8/8/8/8/1r2K3 w - - 0 1",
+			expected: Win(piece.Black, Timeout),
+		},
+		"opponent has two knights": {
+			fen:      "4k3/8/8/8/8/8/8/1n2K1n1 w - - 0 1",
+			expected: Win(piece.Black, Timeout),
+		},
+		// the flagged side's pawn could block its own king in
+		"opponent has a lone knight": {
+			fen:      "4k3/8/8/8/8/8/4P3/1n2K3 w - - 0 1",
+			expected: Win(piece.Black, Timeout),
+		},
+		"opponent has a lone bishop": {
+			fen:      "4k3/8/8/8/8/8/4P3/1b2K3 w - - 0 1",
+			expected: Win(piece.Black, Timeout),
+		},
+		"opponent has a lone knight against a queen": {
+			fen:      "4k3/8/8/8/8/8/8/1n1QK3 w - - 0 1",
+			expected: Draw(Timeout),
+		},
+		"opponent has a lone bishop against a rook": {
+			fen:      "4k3/8/8/8/8/8/8/1b2K2R w - - 0 1",
+			expected: Draw(Timeout),
+		},
+		"opponent has a bare king": {
+			fen:      "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1",
+			expected: Draw(Timeout),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, ft := newTimedTestState(t, test.fen, "1")
+
+			_, over := s.CheckGameOver()
+			assert.False(t, over)
+
+			ft.advance(time.Minute)
+			res, over := s.CheckGameOver()
+			assert.True(t, over)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+}
+
+func TestCheckGameOverMoveAfterFlagFall(t *testing.T) {
+	s, ft := newTimedTestState(t, "7k/7p/6K1/8/8/8/8/R7 w - - 0 1", "1")
+
+	ft.advance(2 * time.Minute)
+	s.PlayMoves([]string{"a1a8"})
+
+	res, over := s.CheckGameOver()
+	assert.True(t, over)
+	assert.Equal(t, Win(piece.Black, Timeout), res)
+}
+
+func TestCheckGameOverStopsClock(t *testing.T) {
+	s, ft := newTimedTestState(t, "7k/8/6K1/8/8/8/8/R7 w - - 0 1", "1")
+	s.PlayMoves([]string{"a1a8"})
+
+	res, over := s.CheckGameOver()
+	assert.True(t, over)
+	assert.Equal(t, Win(piece.White, Checkmate), res)
+
+	// the mated side's flag can't fall afterwards
+	ft.advance(time.Hour)
+	res, _ = s.CheckGameOver()
+	assert.Equal(t, Win(piece.White, Checkmate), res)
+}
+
+func TestTurnContext(t *testing.T) {
+	s, ft := newTimedTestState(t, board.StartingFEN, "1")
+
+	ctx, cancel := s.TurnContext(context.Background())
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, ft.now.Add(time.Minute), deadline)
+
+	ctx, cancel = NewTestStateFromFEN(board.StartingFEN).TurnContext(context.Background())
+	defer cancel()
+
+	_, ok = ctx.Deadline()
+	assert.False(t, ok)
+}
@@ -0,0 +1,174 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+)
+
+// crazyhouse plays by the standard rules once drops are among the moves,
+// which the state and position handle themselves
+type crazyhouse struct {
+	standard
+}
+
+func (crazyhouse) String() string {
+	return "crazyhouse"
+}
+
+func (s State) Crazyhouse() bool {
+	return s.Variant() == Crazyhouse
+}
+
+// movedPiece is the piece m puts on its target, before any promotion
+func (s State) movedPiece(m move.Move) piece.Piece {
+	if m.IsDrop() {
+		return m.Drop * s.ActiveColor
+	}
+
+	return s.Piece(m.Source)
+}
+
+// splitPockets reads crazyhouse's additions to the piece placement: the
+// pockets in brackets after it, and a ~ after each piece that was promoted
+func splitPockets(placement string) (string, map[piece.Piece]piece.Pocket, bitboard.Bitboard, error) {
+	pockets := map[piece.Piece]piece.Pocket{piece.White: {}, piece.Black: {}}
+
+	if i := strings.IndexByte(placement, '['); i != -1 {
+		if !strings.HasSuffix(placement, "]") {
+			return placement, pockets, 0, errors.New("pockets must end with ]")
+		}
+
+		for _, char := range placement[i+1 : len(placement)-1] {
+			p, ok := piece.CharToPiece[unicode.ToLower(char)]
+			if !ok || p == piece.Empty || p == piece.King {
+				return placement, pockets, 0, fmt.Errorf("unexpected %q in pockets", char)
+			}
+
+			color := piece.Black
+			if unicode.IsUpper(char) {
+				color = piece.White
+			}
+
+			pocket := pockets[color]
+			pocket[p]++
+			pockets[color] = pocket
+		}
+
+		placement = placement[:i]
+	}
+
+	var promoted bitboard.Bitboard
+	var stripped strings.Builder
+	var afterPiece bool
+	rank, file := 7, 0
+	for _, char := range placement {
+		switch {
+		case char == '~':
+			if !afterPiece || rank < 0 || file > 8 {
+				return placement, pockets, 0, errors.New("~ must follow a piece")
+			}
+			promoted |= bitboard.SquareBB(rank*8 + file - 1)
+			afterPiece = false
+			continue
+		case char == '/':
+			rank, file = rank-1, 0
+			afterPiece = false
+		case char >= '1' && char <= '8':
+			file += int(char - '0')
+			afterPiece = false
+		default:
+			file++
+			afterPiece = true
+		}
+
+		stripped.WriteRune(char)
+	}
+
+	return stripped.String(), pockets, promoted, nil
+}
+
+// placementField is the piece placement, with crazyhouse's promoted pieces
+// and pockets
+func (s State) placementField() string {
+	if !s.Crazyhouse() {
+		return s.Board.FEN()
+	}
+
+	var placement strings.Builder
+	rank, file := 7, 0
+	for _, char := range s.Board.FEN() {
+		placement.WriteRune(char)
+
+		switch {
+		case char == '/':
+			rank, file = rank-1, 0
+		case char >= '1' && char <= '8':
+			file += int(char - '0')
+		default:
+			if s.promoted.Has(rank*8 + file) {
+				placement.WriteRune('~')
+			}
+			file++
+		}
+	}
+
+	return fmt.Sprintf("%s[%s%s]", placement.String(), s.Pockets[piece.White].FEN(piece.White), s.Pockets[piece.Black].FEN(piece.Black))
+}
+
+func (s *State) addToPocket(color, pieceType piece.Piece) {
+	pocket := s.Pockets[color]
+	pocket[pieceType]++
+	s.Pockets[color] = pocket
+	s.hash ^= bitboard.ZobristPocket(pieceType*color, pocket[pieceType])
+}
+
+func (s *State) takeFromPocket(color, pieceType piece.Piece) {
+	pocket := s.Pockets[color]
+	s.hash ^= bitboard.ZobristPocket(pieceType*color, pocket[pieceType])
+	pocket[pieceType]--
+	s.Pockets[color] = pocket
+}
+
+// handlePocketCapture runs before the board changes. whatever m captures goes
+// to the capturer's pocket, as a pawn if it was promoted, and promoted pieces
+// are followed to their new squares
+func (s *State) handlePocketCapture(m move.Move, mc moveContext) {
+	if !s.Crazyhouse() || m.IsDrop() {
+		return
+	}
+
+	captured := m.Target
+	if mc.enPassantCapture != "" {
+		captured = mc.enPassantCapture
+	}
+
+	capturedIndex := board.SquareToIndex(captured)
+	if p := s.Piece(captured); mc.isCapture && p != piece.Empty {
+		pieceType := p.Type()
+		if s.promoted.Has(capturedIndex) {
+			pieceType = piece.Pawn
+		}
+		s.addToPocket(s.ActiveColor, pieceType)
+	}
+
+	sourceIndex := board.SquareToIndex(m.Source)
+	promoted := s.promoted.Has(sourceIndex) || mc.PromoteTo != piece.Empty
+	s.promoted &^= bitboard.SquareBB(sourceIndex) | bitboard.SquareBB(capturedIndex)
+	if promoted {
+		s.promoted |= bitboard.SquareBB(board.SquareToIndex(m.Target))
+	}
+}
+
+func (s *State) handleDrop(m move.Move, _ moveContext) {
+	p := m.Drop * s.ActiveColor
+	s.takeFromPocket(s.ActiveColor, m.Drop)
+	s.hashSquare(m.Target, p)
+	s.nextBoard[board.SquareToIndex(m.Target)] = p
+}
@@ -0,0 +1,185 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrazyhouseMoves(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		move     string
+		expected string
+	}{
+		"capture goes to the pocket": {
+			fen:      "rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR[] w KQkq d6 0 2",
+			move:     "e4d5",
+			expected: "rnbqkbnr/ppp1pppp/8/3P4/8/8/PPPP1PPP/RNBQKBNR[P] b KQkq - 0 2",
+		},
+		"en passant capture": {
+			fen:      "4k3/8/8/3Pp3/8/8/8/4K3[] w - e6 0 1",
+			move:     "d5e6",
+			expected: "4k3/8/4P3/8/8/8/8/4K3[P] b - - 0 1",
+		},
+		"drop": {
+			fen:      "4k3/8/8/8/8/8/8/4K3[Nb] w - - 0 1",
+			move:     "N@f3",
+			expected: "4k3/8/8/8/8/5N2/8/4K3[b] b - - 1 1",
+		},
+		"pawn drop": {
+			fen:      "4k3/8/8/8/8/8/8/4K3[p] b - - 3 1",
+			move:     "P@e5",
+			expected: "4k3/8/8/4p3/8/8/8/4K3[] w - - 0 2",
+		},
+		"promotion is marked": {
+			fen:      "8/P3k3/8/8/8/8/8/4K3[] w - - 0 1",
+			move:     "a7a8q",
+			expected: "Q~7/4k3/8/8/8/8/8/4K3[] b - - 0 1",
+		},
+		"promoted piece is pocketed as a pawn": {
+			fen:      "r3k3/8/8/8/8/8/8/Q~3K3[] b - - 0 1",
+			move:     "a8a1",
+			expected: "4k3/8/8/8/8/8/8/r3K3[p] w - - 0 2",
+		},
+		"promoted piece stays marked when it moves": {
+			fen:      "4k3/8/8/8/8/8/8/Q~3K3[] w - - 0 1",
+			move:     "a1a4",
+			expected: "4k3/8/8/8/Q~7/8/8/4K3[] b - - 1 1",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewTestStateFromFEN(test.fen, WithCrazyhouse())
+			m, err := move.ParseMove(test.move)
+			assert.NoError(t, err)
+			assert.Contains(t, s.GeneratePossibleMoves(), m)
+
+			hash := s.Hash()
+			s.MakeMove(m)
+			assert.Equal(t, test.expected, s.FEN())
+			assert.Equal(t, s.Position().Hash(), s.Hash())
+
+			s.Undo()
+			assert.Equal(t, test.fen, s.FEN())
+			assert.Equal(t, hash, s.Hash())
+		})
+	}
+}
+
+func TestCrazyhouseDrops(t *testing.T) {
+	tests := map[string]struct {
+		fen   string
+		drops int
+	}{
+		"anywhere empty":             {"4k3/8/8/8/8/8/8/4K3[N] w - - 0 1", 62},
+		"pawns not on the back rank": {"4k3/8/8/8/8/8/8/4K3[P] w - - 0 1", 48},
+		"one of each":                {"4k3/8/8/8/8/8/8/4K3[PN] w - - 0 1", 110},
+		"only the side to move's":    {"4k3/8/8/8/8/8/8/4K3[n] w - - 0 1", 0},
+		"only to block a check":      {"4k3/8/8/8/8/8/8/r3K3[NP] w - - 0 1", 3},
+		"not against a knight":       {"4k3/8/8/8/8/8/2n5/4K3[Q] w - - 0 1", 0},
+		"not in double check":        {"4k3/8/8/8/8/8/2n5/r3K3[Q] w - - 0 1", 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewTestStateFromFEN(test.fen, WithCrazyhouse())
+
+			var drops int
+			for _, m := range s.GeneratePossibleMoves() {
+				if m.IsDrop() {
+					drops++
+				}
+			}
+			assert.Equal(t, test.drops, drops)
+		})
+	}
+}
+
+func TestCrazyhouseFEN(t *testing.T) {
+	s, err := ParseFEN("r1bqk2r/pppp1ppp/2n2n2/2b1p3/2B1P3/5N2/PPPP1PPP/RNBQK2R[QRBNPqrbnp] w KQkq - 4 4", WithCrazyhouse())
+	assert.NoError(t, err)
+	assert.True(t, s.Crazyhouse())
+	assert.Equal(t, piece.Pocket{piece.Pawn: 1, piece.Knight: 1, piece.Bishop: 1, piece.Rook: 1, piece.Queen: 1}, s.Pockets[piece.White])
+
+	// the order of the pocket is normalized
+	s, err = ParseFEN("4k3/8/8/8/8/8/8/4K3[pPqNPp] w - - 0 1", WithCrazyhouse())
+	assert.NoError(t, err)
+	assert.Equal(t, "4k3/8/8/8/8/8/8/4K3[NPPqpp] w - - 0 1", s.FEN())
+
+	// a pocket is empty without brackets
+	s, err = ParseFEN(board.StartingFEN, WithCrazyhouse())
+	assert.NoError(t, err)
+	assert.Equal(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR[] w KQkq - 0 1", s.FEN())
+
+	// more pieces than a side starts with can be dropped back
+	_, err = ParseFEN("4k3/8/8/8/8/8/PPPPPPPP/PPPPK3[] w - - 0 1", WithCrazyhouse(), WithLegalPosition())
+	assert.Error(t, err)
+	_, err = ParseFEN("4k3/8/8/8/8/PPPP4/PPPPPPPP/4K3[] w - - 0 1", WithCrazyhouse(), WithLegalPosition())
+	assert.NoError(t, err)
+}
+
+func TestCrazyhouseFENErrors(t *testing.T) {
+	for _, fen := range []string{
+		"4k3/8/8/8/8/8/8/4K3[K] w - - 0 1",
+		"4k3/8/8/8/8/8/8/4K3[x] w - - 0 1",
+		"4k3/8/8/8/8/8/8/4K3[Q w - - 0 1",
+		"4k3/8/8/8/8/8/8/~4K3[] w - - 0 1",
+		"4k3/8/8/8/8/8/8/4K2~1[] w - - 0 1",
+	} {
+		_, err := ParseFEN(fen, WithCrazyhouse())
+		var fenErr *FENError
+		if assert.ErrorAs(t, err, &fenErr, fen) {
+			assert.Equal(t, "piece placement", fenErr.Field, fen)
+		}
+	}
+
+	// pockets are only read in crazyhouse
+	_, err := ParseFEN("4k3/8/8/8/8/8/8/4K3[Q] w - - 0 1")
+	assert.Error(t, err)
+}
+
+func TestCrazyhouseSAN(t *testing.T) {
+	s := NewTestStateFromFEN("4k3/8/8/8/8/8/8/4K3[NPb] w - - 0 1", WithCrazyhouse())
+
+	assert.Equal(t, "N@f6+", s.SAN(move.NewDropMove(piece.Knight, "f6")))
+	assert.Equal(t, "P@d7+", s.SAN(move.NewDropMove(piece.Pawn, "d7")))
+
+	for san, expected := range map[string]move.Move{
+		"N@f6": move.NewDropMove(piece.Knight, "f6"),
+		"P@e4": move.NewDropMove(piece.Pawn, "e4"),
+		"@e4":  move.NewDropMove(piece.Pawn, "e4"),
+	} {
+		m, err := s.ParseSAN(san)
+		assert.NoError(t, err, san)
+		assert.Equal(t, expected, m, san)
+	}
+
+	for _, san := range []string{"B@e4", "P@e8", "Q@e4"} {
+		_, err := s.ParseSAN(san)
+		assert.ErrorIs(t, err, ErrIllegalSAN, san)
+	}
+
+	s.MakeMove(move.NewDropMove(piece.Knight, "f6"))
+	assert.Equal(t, []string{"N@f6+"}, s.SANMoves())
+}
+
+func TestCrazyhouseGame(t *testing.T) {
+	s := NewTestStateFromFEN(board.StartingFEN, WithCrazyhouse())
+
+	// the pawn taken on d5 is dropped back on the board and taken again
+	for _, san := range []string{"e4", "d5", "exd5", "Qxd5", "Nc3", "Qa5", "P@b4", "Qxb4"} {
+		m, err := s.ParseSAN(san)
+		assert.NoError(t, err, san)
+		s.MakeMove(m)
+		assert.Equal(t, s.Position().Hash(), s.Hash(), san)
+	}
+
+	assert.Equal(t, "rnb1kbnr/ppp1pppp/8/8/1q6/2N5/PPPP1PPP/R1BQKBNR[pp] w KQkq - 0 5", s.FEN())
+	assert.Equal(t, []string{"e4", "d5", "exd5", "Qxd5", "Nc3", "Qa5", "P@b4", "Qxb4"}, s.SANMoves())
+	assert.False(t, s.Position().InsufficientMaterial())
+}
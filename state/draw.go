@@ -0,0 +1,90 @@
+package state
+
+import (
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/player"
+)
+
+// positionKey identifies a position for repetition purposes. it is the hash,
+// except that the en passant file only counts when an en passant capture is
+// actually legal, rather than whenever a pawn stands next to the one that moved
+func (s State) positionKey() uint64 {
+	pos := s.Position()
+	key := s.hash ^ pos.EnPassantKey() ^ s.checksKey()
+
+	if s.canCaptureEnPassant(pos) {
+		key ^= bitboard.ZobristEnPassant(int(s.EnPassantTarget[0] - 'a'))
+	}
+
+	return key
+}
+
+func (s State) canCaptureEnPassant(pos bitboard.Position) bool {
+	if s.EnPassantTarget == noEnPassantTarget {
+		return false
+	}
+
+	switch s.Variant() {
+	case Antichess:
+		// there is no check, so any capture will do
+		return len(pos.EnPassantCaptures(nil)) > 0
+	case Atomic:
+		for _, m := range pos.EnPassantCaptures(nil) {
+			if atomicLegal(pos, m) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return pos.CanCaptureEnPassant()
+}
+
+// Repetitions counts how many times the current position has occurred,
+// including now. pawn moves and captures can't be undone, so only positions
+// since the last of those need to be compared
+func (s State) Repetitions() int {
+	current := len(s.positions) - 1
+	since := current - min(s.HalfmoveClock, len(s.Moves))
+
+	count := 0
+	for i := since; i <= current; i++ {
+		if s.positions[i] == s.positions[current] {
+			count++
+		}
+	}
+
+	return count
+}
+
+// DrawClaim reports a draw the active player may claim, but which doesn't end
+// the game on its own
+func (s State) DrawClaim() (GameResult, bool) {
+	if s.Repetitions() >= 3 {
+		return Draw(ThreefoldRepetition), true
+	}
+
+	if s.HalfmoveClock >= 100 {
+		return Draw(FiftyMoveRule), true
+	}
+
+	return GameResult{}, false
+}
+
+// ClaimDraw asks the active player whether to claim an available draw, and
+// ends the game if they do
+func (s *State) ClaimDraw() (GameResult, bool) {
+	res, ok := s.DrawClaim()
+	if !ok {
+		return GameResult{}, false
+	}
+
+	claimer, ok := s.ActivePlayer().(player.DrawClaimer)
+	if !ok || !claimer.ClaimDraw() {
+		return GameResult{}, false
+	}
+
+	s.EndGame(res)
+
+	return res, true
+}
@@ -0,0 +1,203 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+type drawClaimingPlayer struct {
+	testPlayer
+}
+
+func (d drawClaimingPlayer) ClaimDraw() bool {
+	return true
+}
+
+var knightShuffle = []string{"g1f3", "g8f6", "f3g1", "f6g8"}
+
+func TestRepetitions(t *testing.T) {
+	s := NewTestStateFromFEN(board.StartingFEN)
+	assert.Equal(t, 1, s.Repetitions())
+
+	for i := 2; i <= 5; i++ {
+		s.PlayMoves(knightShuffle)
+		assert.Equal(t, i, s.Repetitions())
+	}
+
+	s.Undo()
+	assert.Equal(t, 4, s.Repetitions())
+}
+
+func TestRepetitionsResetByPawnMove(t *testing.T) {
+	s := NewTestStateFromFEN(board.StartingFEN)
+	s.PlayMoves(knightShuffle)
+	s.PlayMoves([]string{"e2e4", "e7e5"})
+	s.PlayMoves(knightShuffle)
+	assert.Equal(t, 2, s.Repetitions())
+}
+
+func TestRepetitionsIgnoreUncapturableEnPassant(t *testing.T) {
+	s := NewTestStateFromFEN(board.StartingFEN)
+	s.PlayMoves([]string{"e2e4"})
+	assert.Equal(t, "e3", s.EnPassantTarget)
+
+	s.PlayMoves([]string{"g8f6", "g1f3", "f6g8", "f3g1"})
+	assert.Equal(t, 2, s.Repetitions())
+
+	s.PlayMoves([]string{"g8f6", "g1f3", "f6g8", "f3g1"})
+	assert.Equal(t, 3, s.Repetitions())
+}
+
+func TestPositionKey(t *testing.T) {
+	tests := map[string]struct {
+		fen     string
+		without string
+		same    bool
+	}{
+		"uncapturable en passant": {
+			fen:     "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			without: "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1",
+			same:    true,
+		},
+		"capturable en passant": {
+			fen:     "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+			without: "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq - 0 3",
+			same:    false,
+		},
+		"pinned en passant capturer": {
+			fen:     "8/8/8/KPp4r/8/8/8/7k w - c6 0 1",
+			without: "8/8/8/KPp4r/8/8/8/7k w - - 0 1",
+			same:    true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			key := NewTestStateFromFEN(test.fen).positionKey()
+			withoutKey := NewTestStateFromFEN(test.without).positionKey()
+			assert.Equal(t, test.same, key == withoutKey)
+		})
+	}
+}
+
+func TestCheckGameOverFivefoldRepetition(t *testing.T) {
+	s := NewTestStateFromFEN(board.StartingFEN)
+
+	for i := 0; i < 3; i++ {
+		s.PlayMoves(knightShuffle)
+	}
+	_, over := s.CheckGameOver()
+	assert.False(t, over)
+
+	s.PlayMoves(knightShuffle)
+	res, over := s.CheckGameOver()
+	assert.True(t, over)
+	assert.Equal(t, Draw(FivefoldRepetition), res)
+}
+
+func TestCheckGameOverSeventyFiveMoveRule(t *testing.T) {
+	s := NewTestStateFromFEN("4k3/8/8/8/8/8/8/R3K3 w - - 149 100")
+	_, over := s.CheckGameOver()
+	assert.False(t, over)
+
+	s.PlayMoves([]string{"a1a2"})
+	res, over := s.CheckGameOver()
+	assert.True(t, over)
+	assert.Equal(t, Draw(SeventyFiveMoveRule), res)
+}
+
+func TestCheckGameOverCheckmateBeatsSeventyFiveMoveRule(t *testing.T) {
+	s := NewTestStateFromFEN("7k/8/6K1/8/8/8/8/R7 w - - 149 100")
+	s.PlayMoves([]string{"a1a8"})
+	res, over := s.CheckGameOver()
+	assert.True(t, over)
+	assert.Equal(t, Win(piece.White, Checkmate), res)
+}
+
+func TestDrawClaim(t *testing.T) {
+	tests := map[string]struct {
+		fen      string
+		moves    []string
+		expected GameResult
+		ok       bool
+	}{
+		"nothing to claim": {
+			fen:   board.StartingFEN,
+			moves: knightShuffle,
+		},
+		"threefold repetition": {
+			fen:      board.StartingFEN,
+			moves:    append(knightShuffle, knightShuffle...),
+			expected: Draw(ThreefoldRepetition),
+			ok:       true,
+		},
+		"fifty-move rule": {
+			fen:      "4k3/8/8/8/8/8/8/R3K3 w - - 99 60",
+			moves:    []string{"a1a2"},
+			expected: Draw(FiftyMoveRule),
+			ok:       true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewTestStateFromFEN(test.fen)
+			s.PlayMoves(test.moves)
+
+			res, ok := s.DrawClaim()
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, res)
+
+			_, over := s.CheckGameOver()
+			assert.False(t, over)
+		})
+	}
+}
+
+func TestClaimDraw(t *testing.T) {
+	s := NewTestStateFromFEN(board.StartingFEN)
+	s.PlayMoves(append(knightShuffle, knightShuffle...))
+
+	// the test player never claims
+	_, claimed := s.ClaimDraw()
+	assert.False(t, claimed)
+
+	s.Players[s.ActiveColor] = drawClaimingPlayer{}
+	res, claimed := s.ClaimDraw()
+	assert.True(t, claimed)
+	assert.Equal(t, Draw(ThreefoldRepetition), res)
+
+	res, over := s.CheckGameOver()
+	assert.True(t, over)
+	assert.Equal(t, Draw(ThreefoldRepetition), res)
+	assert.Equal(t, "1/2-1/2", res.PGN())
+	assert.Equal(t, "draw by threefold repetition", res.String())
+}
+
+func TestCheckGameOverInsufficientMaterial(t *testing.T) {
+	s := NewTestStateFromFEN("8/8/4k3/8/8/3K4/8/5r2 w - - 0 1")
+	_, over := s.CheckGameOver()
+	assert.False(t, over)
+
+	s.PlayMoves([]string{"d3e2", "e6e5", "e2f1"})
+	res, over := s.CheckGameOver()
+	assert.True(t, over)
+	assert.Equal(t, Draw(InsufficientMaterial), res)
+	assert.Equal(t, "1/2-1/2", res.PGN())
+}
+
+func TestCheckGameOverDeadPosition(t *testing.T) {
+	fen := "8/4k3/8/p1p1p1p1/P1P1P1P1/8/4K3/8 w - - 0 1"
+
+	s := NewTestStateFromFEN(fen)
+	_, over := s.CheckGameOver()
+	assert.False(t, over)
+
+	s.DeadPositions = true
+	res, over := s.CheckGameOver()
+	assert.True(t, over)
+	assert.Equal(t, Draw(DeadPosition), res)
+}
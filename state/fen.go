@@ -0,0 +1,593 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ethansaxenian/chess/assert"
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/ethansaxenian/chess/player"
+)
+
+// FENError says which field of a FEN is invalid: "fields", "piece placement",
+// "active color", "castling rights", "en passant target", "checks", "halfmove
+// clock" or "fullmove number". Square is set when the problem is about one square
+type FENError struct {
+	FEN    string
+	Field  string
+	Square string
+	Err    error
+}
+
+func (e *FENError) Error() string {
+	if e.Square != "" {
+		return fmt.Sprintf("invalid FEN %q: %s: %s: %v", e.FEN, e.Field, e.Square, e.Err)
+	}
+
+	return fmt.Sprintf("invalid FEN %q: %s: %v", e.FEN, e.Field, e.Err)
+}
+
+func (e *FENError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError holds every problem found in a FEN, in field order
+type ValidationError struct {
+	FEN      string
+	Problems []*FENError
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		if p.Square != "" {
+			problems = append(problems, fmt.Sprintf("%s: %s: %v", p.Field, p.Square, p.Err))
+		} else {
+			problems = append(problems, fmt.Sprintf("%s: %v", p.Field, p.Err))
+		}
+	}
+
+	return fmt.Sprintf("invalid FEN %q: %s", e.FEN, strings.Join(problems, "; "))
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		errs = append(errs, p)
+	}
+	return errs
+}
+
+type FENOptions struct {
+	// Lenient accepts FENs without the halfmove clock and fullmove number,
+	// which default to 0 and 1
+	Lenient bool
+	// Legal rejects positions that can't arise in a game
+	Legal bool
+	// Chess960 castles with whichever rooks the castling rights name, in
+	// X-FEN (KQkq for the outermost rooks) or Shredder-FEN (rook files)
+	Chess960 bool
+	// Variant is the rules the state plays by, nil for standard chess.
+	// crazyhouse reads the pockets in brackets after the piece placement,
+	// e.g. [Qnp], and promoted pieces marked with a ~. three-check reads the
+	// checks left after the en passant target, e.g. 3+2
+	Variant Variant
+}
+
+func WithLenientFEN() func(*FENOptions) {
+	return func(o *FENOptions) {
+		o.Lenient = true
+	}
+}
+
+func WithLegalPosition() func(*FENOptions) {
+	return func(o *FENOptions) {
+		o.Legal = true
+	}
+}
+
+func WithChess960() func(*FENOptions) {
+	return func(o *FENOptions) {
+		o.Chess960 = true
+	}
+}
+
+func WithVariant(v Variant) func(*FENOptions) {
+	return func(o *FENOptions) {
+		o.Variant = v
+	}
+}
+
+func WithCrazyhouse() func(*FENOptions) {
+	return WithVariant(Crazyhouse)
+}
+
+// ParseFEN is StartingStateFromFEN for untrusted input. the returned state has
+// no players yet
+func ParseFEN(fen string, opts ...func(*FENOptions)) (*State, error) {
+	var o FENOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	s := &State{
+		Players: map[piece.Piece]player.Player{
+			piece.White: nil,
+			piece.Black: nil,
+		},
+	}
+
+	if err := s.loadFEN(fen, o); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// ValidateFEN reports every syntax problem in a FEN and, once the board can be
+// read, everything that makes the position impossible to reach in a game
+func ValidateFEN(fen string, opts ...func(*FENOptions)) error {
+	o := FENOptions{Legal: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	_, problems := parseFEN(fen, o)
+	if len(problems) > 0 {
+		return &ValidationError{fen, problems}
+	}
+
+	return nil
+}
+
+type parsedFEN struct {
+	board           board.Chessboard
+	activeColor     piece.Piece
+	castling        map[piece.Piece]map[piece.Side]bool
+	setup           *bitboard.CastlingSetup
+	variant         Variant
+	pockets         map[piece.Piece]piece.Pocket
+	promoted        bitboard.Bitboard
+	enPassantTarget string
+	checks          map[piece.Piece]int
+	halfmoveClock   int
+	fullmoveNumber  int
+}
+
+func parseFEN(fen string, o FENOptions) (parsedFEN, []*FENError) {
+	p := parsedFEN{
+		castling: map[piece.Piece]map[piece.Side]bool{
+			piece.White: {},
+			piece.Black: {},
+		},
+		enPassantTarget: noEnPassantTarget,
+		fullmoveNumber:  1,
+		variant:         o.Variant,
+	}
+	if p.variant == nil {
+		p.variant = Standard
+	}
+
+	var problems []*FENError
+	problem := func(field, square string, err error) {
+		problems = append(problems, &FENError{FEN: fen, Field: field, Square: square, Err: err})
+	}
+
+	fenFields := strings.Fields(fen)
+	if p.variant == ThreeCheck {
+		var err error
+		if fenFields, p.checks, err = splitChecks(fenFields); err != nil {
+			problem("checks", "", err)
+		}
+	}
+
+	switch {
+	case len(fenFields) == 6:
+	case o.Lenient && len(fenFields) >= 4 && len(fenFields) < 6:
+		fenFields = append(fenFields, []string{"0", "1"}[len(fenFields)-4:]...)
+	default:
+		problem("fields", "", fmt.Errorf("expected 6 fields, got %d", len(fenFields)))
+		return p, problems
+	}
+
+	placement := fenFields[0]
+	if p.variant == Crazyhouse {
+		var err error
+		if placement, p.pockets, p.promoted, err = splitPockets(placement); err != nil {
+			problem("piece placement", "", err)
+		}
+	}
+
+	placementErrs := board.ValidatePiecePlacement(placement)
+	for _, err := range placementErrs {
+		problem("piece placement", err.Square, err)
+	}
+	p.board, _ = board.ParsePiecePlacement(placement)
+
+	switch fenFields[1] {
+	case "w":
+		p.activeColor = piece.White
+	case "b":
+		p.activeColor = piece.Black
+	default:
+		problem("active color", "", fmt.Errorf("expected w or b, got %q", fenFields[1]))
+	}
+
+	// the rooks castling rights refer to, for chess960
+	rooks := map[piece.Piece]*bitboard.CastlingSquares{}
+
+	if fenFields[2] != "-" {
+		for _, char := range fenFields[2] {
+			var color piece.Piece
+			var side piece.Side
+
+			switch {
+			case char == 'K':
+				color, side = piece.White, piece.Kingside
+			case char == 'Q':
+				color, side = piece.White, piece.Queenside
+			case char == 'k':
+				color, side = piece.Black, piece.Kingside
+			case char == 'q':
+				color, side = piece.Black, piece.Queenside
+			case o.Chess960 && char >= 'A' && char <= 'H':
+				color = piece.White
+			case o.Chess960 && char >= 'a' && char <= 'h':
+				color = piece.Black
+			default:
+				problem("castling rights", "", fmt.Errorf("unexpected %q", char))
+				continue
+			}
+
+			if o.Chess960 && len(placementErrs) == 0 {
+				var err error
+				if side, err = p.chess960Rook(rooks, color, char); err != nil {
+					problem("castling rights", "", err)
+					continue
+				}
+			}
+
+			if p.castling[color][side] {
+				problem("castling rights", "", fmt.Errorf("duplicate %q", char))
+			}
+			p.castling[color][side] = true
+		}
+	}
+
+	// there is no castling in antichess, whatever the FEN says
+	if p.variant == Antichess {
+		p.castling[piece.White] = map[piece.Side]bool{}
+		p.castling[piece.Black] = map[piece.Side]bool{}
+	}
+
+	if o.Chess960 {
+		squares := [2]bitboard.CastlingSquares{
+			{King: 4, KingsideRook: 7, QueensideRook: 0},
+			{King: 60, KingsideRook: 63, QueensideRook: 56},
+		}
+		for i, color := range piece.AllColors {
+			if rooks[color] != nil {
+				squares[i] = *rooks[color]
+			}
+		}
+		p.setup = bitboard.NewCastlingSetup(squares[0], squares[1], true)
+	}
+
+	if target := fenFields[3]; target != noEnPassantTarget {
+		if _, err := board.ParseSquare(target); err != nil {
+			problem("en passant target", "", err)
+		} else if err := p.checkEnPassant(target, len(placementErrs) == 0); err != nil {
+			problem("en passant target", target, err)
+		} else {
+			p.enPassantTarget = target
+		}
+	}
+
+	halfmoveClock, err := strconv.Atoi(fenFields[4])
+	if err == nil && halfmoveClock < 0 {
+		err = fmt.Errorf("%d is negative", halfmoveClock)
+	}
+	if err != nil {
+		problem("halfmove clock", "", err)
+	}
+	p.halfmoveClock = halfmoveClock
+
+	fullmoveNumber, err := strconv.Atoi(fenFields[5])
+	if err == nil && fullmoveNumber < 1 {
+		err = fmt.Errorf("%d is less than 1", fullmoveNumber)
+	}
+	if err != nil {
+		problem("fullmove number", "", err)
+	}
+	p.fullmoveNumber = fullmoveNumber
+
+	// legality only makes sense on a board we could read
+	if o.Legal && len(placementErrs) == 0 {
+		for _, lp := range p.legalityProblems() {
+			lp.FEN = fen
+			problems = append(problems, lp)
+		}
+	}
+
+	return p, problems
+}
+
+var colorNames = map[piece.Piece]string{piece.White: "white", piece.Black: "black"}
+
+func (p parsedFEN) legalityProblems() []*FENError {
+	var problems []*FENError
+	problem := func(field, square string, format string, args ...any) {
+		problems = append(problems, &FENError{Field: field, Square: square, Err: fmt.Errorf(format, args...)})
+	}
+
+	for _, color := range piece.AllColors {
+		counts := map[piece.Piece]int{}
+		var total int
+
+		for i, pc := range p.board {
+			if pc.Color() != color {
+				continue
+			}
+
+			counts[pc.Type()]++
+			total++
+
+			if rank := i / 8; pc.Type() == piece.Pawn && (rank == 0 || rank == 7) {
+				problem("piece placement", bitboard.SquareName(i), "%s pawn on the back rank", colorNames[color])
+			}
+		}
+
+		// antichess kings are ordinary pieces
+		if counts[piece.King] != 1 && p.variant != Antichess {
+			problem("piece placement", "", "%s has %d kings", colorNames[color], counts[piece.King])
+		}
+
+		// dropped pieces can outnumber a side's starting set in crazyhouse
+		if p.variant != Crazyhouse {
+			if counts[piece.Pawn] > 8 {
+				problem("piece placement", "", "%s has %d pawns", colorNames[color], counts[piece.Pawn])
+			}
+
+			if total > 16 {
+				problem("piece placement", "", "%s has %d pieces", colorNames[color], total)
+			}
+
+			// every piece beyond the starting set must have been a pawn
+			promoted := max(counts[piece.Queen]-1, 0) + max(counts[piece.Rook]-2, 0) + max(counts[piece.Bishop]-2, 0) + max(counts[piece.Knight]-2, 0)
+			if counts[piece.Pawn] <= 8 && promoted > 8-counts[piece.Pawn] {
+				problem("piece placement", "", "%s has %d promoted pieces but only %d missing pawns", colorNames[color], promoted, 8-counts[piece.Pawn])
+			}
+		}
+
+		for _, side := range []piece.Side{piece.Kingside, piece.Queenside} {
+			if !p.castling[color][side] {
+				continue
+			}
+
+			setup := p.setup
+			if setup == nil {
+				setup = bitboard.StandardCastling
+			}
+
+			if king := bitboard.SquareName(setup.King(color)); p.board.Square(king) != piece.King*color {
+				problem("castling rights", king, "castling needs the %s king on its home square", colorNames[color])
+			}
+
+			if rook := bitboard.SquareName(setup.Rook(color, side)); p.board.Square(rook) != piece.Rook*color {
+				problem("castling rights", rook, "castling needs a %s rook on its home square", colorNames[color])
+			}
+		}
+	}
+
+	if p.activeColor == piece.Empty {
+		return problems
+	}
+
+	waiting := p.activeColor * -1
+	inCheck := bitboard.NewPosition(p.board, waiting, 0, bitboard.NoSquare).InCheck()
+	switch p.variant {
+	case Atomic:
+		inCheck = atomicInCheck(bitboard.NewPosition(p.board, waiting, 0, bitboard.NoSquare))
+	case Antichess:
+		inCheck = false
+	}
+	if inCheck {
+		problem("active color", "", "%s is in check but it is %s's turn", colorNames[waiting], colorNames[p.activeColor])
+	}
+
+	// the target itself was checked while parsing
+	if p.enPassantTarget != noEnPassantTarget && p.halfmoveClock != 0 {
+		problem("halfmove clock", "", "must be 0 right after a pawn move, got %d", p.halfmoveClock)
+	}
+
+	return problems
+}
+
+// checkEnPassant makes sure target is behind a pawn of the waiting side that
+// could just have moved two squares, so that a capture there has a pawn to
+// take. the board is only looked at when it could be read
+func (p parsedFEN) checkEnPassant(target string, readBoard bool) error {
+	if p.activeColor == piece.Empty {
+		if target[1] != '3' && target[1] != '6' {
+			return errors.New("not on rank 3 or 6")
+		}
+		return nil
+	}
+
+	// the pawn that just moved belongs to the side that is waiting
+	waiting := p.activeColor * -1
+	expectedRank := map[piece.Piece]int{piece.White: 6, piece.Black: 3}[p.activeColor]
+	if rank := int(target[1] - '0'); rank != expectedRank {
+		return fmt.Errorf("must be on rank %d when %s is to move", expectedRank, colorNames[p.activeColor])
+	}
+
+	if !readBoard {
+		return nil
+	}
+
+	pushed := board.AddRank(target, int(waiting))
+	origin := board.AddRank(target, -int(waiting))
+
+	if p.board.Square(pushed) != piece.Pawn*waiting {
+		return fmt.Errorf("no %s pawn on %s", colorNames[waiting], pushed)
+	}
+
+	if p.board.Square(target) != piece.Empty || p.board.Square(origin) != piece.Empty {
+		return fmt.Errorf("the pawn couldn't have just moved through %s", target)
+	}
+
+	return nil
+}
+
+// chess960Rook finds the rook a castling right refers to, either by its file
+// or as the outermost rook on one side of the king, and which side it is on
+func (p parsedFEN) chess960Rook(rooks map[piece.Piece]*bitboard.CastlingSquares, color piece.Piece, char rune) (piece.Side, error) {
+	backRank := 0
+	if color == piece.Black {
+		backRank = 56
+	}
+
+	squares := rooks[color]
+	if squares == nil {
+		king := -1
+		for sq := backRank; sq < backRank+8; sq++ {
+			if p.board[sq] == piece.King*color {
+				king = sq
+			}
+		}
+
+		if king == -1 {
+			return 0, fmt.Errorf("castling needs the %s king on its back rank", colorNames[color])
+		}
+
+		squares = &bitboard.CastlingSquares{King: king, KingsideRook: backRank + 7, QueensideRook: backRank}
+		rooks[color] = squares
+	}
+
+	rook := -1
+	switch char {
+	case 'K', 'k':
+		for sq := backRank + 7; sq > squares.King && rook == -1; sq-- {
+			if p.board[sq] == piece.Rook*color {
+				rook = sq
+			}
+		}
+	case 'Q', 'q':
+		for sq := backRank; sq < squares.King && rook == -1; sq++ {
+			if p.board[sq] == piece.Rook*color {
+				rook = sq
+			}
+		}
+	default:
+		file := int(unicode.ToLower(char) - 'a')
+		if p.board[backRank+file] == piece.Rook*color {
+			rook = backRank + file
+		}
+	}
+
+	if rook == -1 || rook == squares.King {
+		return 0, fmt.Errorf("no %s rook to castle with for %q", colorNames[color], char)
+	}
+
+	if rook > squares.King {
+		squares.KingsideRook = rook
+		return piece.Kingside, nil
+	}
+
+	squares.QueensideRook = rook
+	return piece.Queenside, nil
+}
+
+// castlingField names the rooks by file in Shredder-FEN. X-FEN only does
+// when another rook is further out on the same side
+func (s State) castlingField(shredder bool) string {
+	setup := s.castlingSetup()
+
+	var castling string
+	for _, color := range piece.AllColors {
+		for _, side := range []piece.Side{piece.Kingside, piece.Queenside} {
+			if !s.Castling[color][side] {
+				continue
+			}
+
+			symbol := map[piece.Side]rune{piece.Kingside: 'k', piece.Queenside: 'q'}[side]
+			rook := setup.Rook(color, side)
+			if shredder || setup.Chess960() && s.outerRook(color, side, rook) {
+				symbol = rune('a' + rook%8)
+			}
+
+			if color == piece.White {
+				symbol = unicode.ToUpper(symbol)
+			}
+			castling += string(symbol)
+		}
+	}
+
+	if castling == "" {
+		return "-"
+	}
+
+	return castling
+}
+
+// outerRook is whether color has another rook on its back rank beyond rook
+func (s State) outerRook(color piece.Piece, side piece.Side, rook int) bool {
+	backRank := rook / 8 * 8
+	for sq := backRank; sq < backRank+8; sq++ {
+		beyond := sq > rook
+		if side == piece.Queenside {
+			beyond = sq < rook
+		}
+
+		if beyond && s.Board[sq] == piece.Rook*color {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ShredderFEN is FEN with castling rights named by the rooks' files, as most
+// chess960 software expects
+func (s State) ShredderFEN() string {
+	fields := strings.Fields(s.FEN())
+	fields[2] = s.castlingField(true)
+	return strings.Join(fields, " ")
+}
+
+func (s *State) loadFEN(fen string, o FENOptions) error {
+	p, problems := parseFEN(fen, o)
+	if len(problems) > 0 {
+		return &ValidationError{fen, problems}
+	}
+
+	s.Board = p.board
+	s.nextBoard = p.board
+	s.ActiveColor = p.activeColor
+	s.Castling = p.castling
+	s.setup = p.setup
+	s.variant = p.variant
+	s.checks = p.checks
+	s.Pockets = p.pockets
+	s.promoted = p.promoted
+	s.EnPassantTarget = p.enPassantTarget
+	s.HalfmoveClock = p.halfmoveClock
+	s.FullmoveNumber = p.fullmoveNumber
+	s.hash = s.Position().Hash()
+
+	// the normalized FEN, so it can always be loaded strictly
+	s.startingFEN = s.FEN()
+	s.Moves = nil
+	s.history = nil
+	s.positions = []uint64{s.positionKey()}
+	s.result = GameResult{}
+	assert.AddContext("FEN", fenContext{s})
+	assert.AddContext("moves", s.Moves)
+
+	return nil
+}
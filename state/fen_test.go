@@ -0,0 +1,204 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFEN(t *testing.T) {
+	s, err := ParseFEN(board.StartingFEN)
+	assert.NoError(t, err)
+	assert.Equal(t, board.StartingFEN, s.FEN())
+	assert.Equal(t, board.StartingFEN, s.StartingFEN())
+	assert.Contains(t, s.Players, piece.White)
+	assert.Contains(t, s.Players, piece.Black)
+}
+
+func TestParseFENErrors(t *testing.T) {
+	tests := map[string]struct {
+		fen   string
+		field string
+	}{
+		"empty":                    {"", "fields"},
+		"missing clocks":           {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -", "fields"},
+		"extra field":              {board.StartingFEN + " 1", "fields"},
+		"nine squares":             {"rnbqkbnrr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "piece placement"},
+		"unknown piece":            {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNX w KQkq - 0 1", "piece placement"},
+		"active color":             {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1", "active color"},
+		"unknown castling right":   {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkx - 0 1", "castling rights"},
+		"duplicate castling right": {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KK - 0 1", "castling rights"},
+		"en passant square":        {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e9 0 1", "en passant target"},
+		"en passant rank":          {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e4 0 1", "en passant target"},
+		"en passant side to move":  {"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e3 0 1", "en passant target"},
+		"en passant without pawn":  {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq e3 0 1", "en passant target"},
+		"en passant through piece": {"rnbqkbnr/pppp1ppp/4n3/4p3/8/8/PPPPPPPP/RNBQKBNR w KQkq e6 0 1", "en passant target"},
+		"halfmove clock":           {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - x 1", "halfmove clock"},
+		"negative halfmove clock":  {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - -1 1", "halfmove clock"},
+		"fullmove number":          {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 0", "fullmove number"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := ParseFEN(test.fen)
+			assert.Nil(t, s)
+
+			var fenErr *FENError
+			if assert.ErrorAs(t, err, &fenErr) {
+				assert.Equal(t, test.field, fenErr.Field)
+				assert.Equal(t, test.fen, fenErr.FEN)
+			}
+		})
+	}
+}
+
+func TestParseFENPlacementError(t *testing.T) {
+	_, err := ParseFEN("8/8/8/8/8/8/8/9 w - - 0 1")
+
+	var placementErr *board.PlacementError
+	if assert.ErrorAs(t, err, &placementErr) {
+		assert.Equal(t, 1, placementErr.Rank)
+	}
+}
+
+func TestParseFENLenient(t *testing.T) {
+	_, err := ParseFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -")
+	assert.Error(t, err)
+
+	s, err := ParseFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -", WithLenientFEN())
+	assert.NoError(t, err)
+	assert.Equal(t, board.StartingFEN, s.FEN())
+
+	s, err = ParseFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 5", WithLenientFEN())
+	assert.NoError(t, err)
+	assert.Equal(t, 5, s.HalfmoveClock)
+	assert.Equal(t, 1, s.FullmoveNumber)
+
+	// the normalized FEN is what gets undone back to
+	s, err = ParseFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR  w KQkq -", WithLenientFEN())
+	assert.NoError(t, err)
+	s.PlayMoves([]string{"e2e4"})
+	s.Undo()
+	assert.Equal(t, board.StartingFEN, s.FEN())
+
+	_, err = ParseFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQ", WithLenientFEN())
+	assert.Error(t, err)
+}
+
+func TestParseFENLegalPosition(t *testing.T) {
+	_, err := ParseFEN("8/8/8/8/8/8/8/8 w - - 0 1")
+	assert.NoError(t, err)
+
+	_, err = ParseFEN("8/8/8/8/8/8/8/8 w - - 0 1", WithLegalPosition())
+	assert.Error(t, err)
+
+	_, err = ParseFEN(board.StartingFEN, WithLegalPosition())
+	assert.NoError(t, err)
+}
+
+func TestValidateFEN(t *testing.T) {
+	type problem struct {
+		field, square string
+	}
+
+	tests := map[string]struct {
+		fen      string
+		problems []problem
+	}{
+		"starting position": {
+			fen: board.StartingFEN,
+		},
+		"kiwipete": {
+			fen: "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		},
+		"valid en passant": {
+			fen: "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+		},
+		"every syntax problem": {
+			fen: "rnbqkbnrr/pppxpppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkz e9 a -3",
+			problems: []problem{
+				{"piece placement", ""},
+				{"piece placement", "d7"},
+				{"active color", ""},
+				{"castling rights", ""},
+				{"en passant target", ""},
+				{"halfmove clock", ""},
+				{"fullmove number", ""},
+			},
+		},
+		"missing kings": {
+			fen:      "8/8/8/8/8/8/8/8 w - - 0 1",
+			problems: []problem{{"piece placement", ""}, {"piece placement", ""}},
+		},
+		"two kings": {
+			fen:      "4k3/8/8/8/8/8/8/3KK3 w - - 0 1",
+			problems: []problem{{"piece placement", ""}},
+		},
+		"pawns on the back rank": {
+			fen:      "P3k3/8/8/8/8/8/8/4K2p w - - 0 1",
+			problems: []problem{{"piece placement", "a8"}, {"piece placement", "h1"}},
+		},
+		"nine pawns": {
+			fen:      "4k3/8/8/8/8/P7/PPPPPPPP/4K3 w - - 0 1",
+			problems: []problem{{"piece placement", ""}},
+		},
+		"too many promoted pieces": {
+			fen:      "4k3/8/8/8/8/8/PPPPPP2/QQQQK3 w - - 0 1",
+			problems: []problem{{"piece placement", ""}},
+		},
+		"side to move in check": {
+			fen: "4k3/8/8/8/8/8/8/4R1K1 b - - 0 1",
+		},
+		"opponent in check": {
+			fen:      "4k3/4R3/8/8/8/8/8/4K3 w - - 0 1",
+			problems: []problem{{"active color", ""}},
+		},
+		"castling without rooks": {
+			fen:      "4k3/8/8/8/8/8/8/4K3 w KQ - 0 1",
+			problems: []problem{{"castling rights", "h1"}, {"castling rights", "a1"}},
+		},
+		"castling with the king moved": {
+			fen:      "r3k2r/8/8/8/8/8/8/R2K3R w K - 0 1",
+			problems: []problem{{"castling rights", "e1"}},
+		},
+		"en passant on the wrong rank": {
+			fen:      "4k3/8/8/8/4P3/8/8/4K3 w - e3 0 1",
+			problems: []problem{{"en passant target", "e3"}},
+		},
+		"en passant without a pawn": {
+			fen:      "4k3/8/8/8/8/8/8/4K3 w - e6 0 1",
+			problems: []problem{{"en passant target", "e6"}},
+		},
+		"en passant through a piece": {
+			fen:      "4k3/4n3/8/4p3/8/8/8/4K3 w - e6 0 1",
+			problems: []problem{{"en passant target", "e6"}},
+		},
+		"en passant with a running clock": {
+			fen:      "4k3/8/8/4p3/8/8/8/4K3 w - e6 3 1",
+			problems: []problem{{"halfmove clock", ""}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateFEN(test.fen)
+			if len(test.problems) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErr *ValidationError
+			if !assert.ErrorAs(t, err, &validationErr) {
+				return
+			}
+
+			var actual []problem
+			for _, p := range validationErr.Problems {
+				actual = append(actual, problem{p.Field, p.Square})
+			}
+			assert.Equal(t, test.problems, actual)
+		})
+	}
+}
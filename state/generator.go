@@ -3,8 +3,10 @@ package state
 import (
 	"fmt"
 	"math"
+	"slices"
 
 	"github.com/ethansaxenian/chess/assert"
+	"github.com/ethansaxenian/chess/bitboard"
 	"github.com/ethansaxenian/chess/board"
 	"github.com/ethansaxenian/chess/move"
 	"github.com/ethansaxenian/chess/piece"
@@ -45,7 +47,32 @@ func generateTmpMoves(state State) []move.Move {
 
 		for _, target := range precomputedPieceMoves[p][source] {
 			m := move.NewMove(source, target)
-			if validateMove(state, m) {
+			if !validateMove(state, m) {
+				continue
+			}
+
+			if p.Type() == piece.Pawn && m.TargetRank() == piece.MaxPawnRank[p] {
+				for _, promotion := range piece.PossiblePromotions {
+					moves = append(moves, move.NewPromotionMove(source, target, promotion))
+				}
+			} else {
+				moves = append(moves, m)
+			}
+		}
+	}
+
+	// chess960 castles are written as the king taking its own rook, which
+	// isn't in the precomputed moves unless the two are next to each other
+	if state.Chess960() {
+		color := state.ActiveColor
+		king := bitboard.SquareName(state.castlingSetup().King(color))
+		for _, side := range []piece.Side{piece.Kingside, piece.Queenside} {
+			m := move.NewMove(king, state.castlingRook(color, side))
+			if state.Piece(m.Source) != piece.King*color || state.Piece(m.Target) != piece.Rook*color {
+				continue
+			}
+
+			if !slices.Contains(moves, m) && validateKingMoveWithState(state, m) {
 				moves = append(moves, m)
 			}
 		}
@@ -56,6 +83,32 @@ func generateTmpMoves(state State) []move.Move {
 	return moves
 }
 
+// generateLegalMovesByMakeUndo is the original generator, which plays every
+// candidate and checks whether the opponent could then capture the king
+func generateLegalMovesByMakeUndo(s State) []move.Move {
+	moves := []move.Move{}
+
+	for _, m := range generateTmpMoves(s) {
+		s.makeMove(m)
+
+		var capturedKing bool
+		for _, nextMove := range generateTmpMoves(s) {
+			if s.Piece(nextMove.Target) == piece.King*s.ActiveColor*-1 {
+				capturedKing = true
+				break
+			}
+		}
+
+		if !capturedKing {
+			moves = append(moves, m)
+		}
+
+		s.undo()
+	}
+
+	return moves
+}
+
 func validateMove(state State, m move.Move) bool {
 	srcPiece := state.Piece(m.Source)
 	targetPiece := state.Piece(m.Target)
@@ -350,34 +403,89 @@ func validateQueenMoveWithState(s State, m move.Move) bool {
 	return validateBishopMoveWithState(s, m) || validateRookMoveWithState(s, m)
 }
 
+// squaresBetween lists the squares from a to b on the same rank, both included
+func squaresBetween(a, b string) []string {
+	step := 1
+	if b[0] < a[0] {
+		step = -1
+	}
+
+	squares := []string{a}
+	for sq := a; sq != b; {
+		sq = board.AddFile(sq, step)
+		squares = append(squares, sq)
+	}
+
+	return squares
+}
+
 func validateKingMoveWithState(s State, m move.Move) bool {
 	color := s.Piece(m.Source).Color()
 
-	startingSquare := piece.StartingKingSquares[color]
-	castlingSquares := piece.CastlingSquares[color]
-	intermediateSquares := piece.CastlingIntermediateSquares[color]
-	castlingRights := s.Castling[color]
+	side, castling := s.castleSide(m)
+	if !castling {
+		// only castling moves the king more than one file
+		return math.Abs(float64(int(m.TargetFile())-int(m.SourceFile()))) <= 1
+	}
 
-	if m.Source == startingSquare {
-		for _, side := range [2]piece.Side{piece.Kingside, piece.Queenside} {
-			// not trying to castle
-			if m.Target != castlingSquares[side] {
-				continue
-			}
+	// can't castle
+	if !s.Castling[color][side] {
+		return false
+	}
 
-			// can't castle
-			if !castlingRights[side] {
-				return false
-			}
+	rookSource := s.castlingRook(color, side)
+	kingTarget := piece.CastlingSquares[color][side]
+	rookTarget := piece.RookCastlingSquares[color][side]
 
-			// blocking pieces
-			for _, square := range intermediateSquares[side] {
-				if s.Piece(square) != piece.Empty {
-					return false
-				}
-			}
+	// blocking pieces, other than the castling king and rook
+	for _, square := range slices.Concat(squaresBetween(m.Source, kingTarget), squaresBetween(rookSource, rookTarget)) {
+		if square != m.Source && square != rookSource && s.Piece(square) != piece.Empty {
+			return false
+		}
+	}
+
+	// no castling out of, through or into check, even if the rook was in the way
+	s.Board[board.SquareToIndex(rookSource)] = piece.Empty
+	for _, square := range squaresBetween(m.Source, kingTarget) {
+		if isSquareAttacked(s, square, color*-1) {
+			return false
 		}
 	}
 
 	return true
 }
+
+func isSquareAttacked(s State, square string, by piece.Piece) bool {
+	for source, p := range s.Board.Squares() {
+		if p == piece.Empty || p.Color() != by {
+			continue
+		}
+
+		if !slices.Contains(precomputedPieceMoves[p][source], square) {
+			continue
+		}
+
+		m := move.NewMove(source, square)
+
+		switch p.Type() {
+		case piece.Pawn:
+			// pawns only attack diagonally
+			if m.SourceFile() != m.TargetFile() && m.TargetRank()-m.SourceRank() == int(by) {
+				return true
+			}
+		case piece.King:
+			// castling isn't an attack
+			if math.Abs(float64(int(m.TargetFile())-int(m.SourceFile()))) <= 1 {
+				return true
+			}
+		case piece.Knight:
+			return true
+		default:
+			if validatePieceMoveWithState(s, p, m) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -261,7 +261,9 @@ func TestValidatePawnMoveWithStateEnPassant(t *testing.T) {
 	assert.True(t, validatePawnMoveWithState(s, move.NewMove("d5", "d6")), "d5 d6")
 	assert.False(t, validatePawnMoveWithState(s, move.NewMove("d5", "c6")), "d5 c6")
 
-	s = *NewTestStateFromFEN("8/8/4p3/3P4/8/8/8/8 w - e6 0 1")
+	// a FEN can't put the target there, since no pawn could just have moved
+	s = *NewTestStateFromFEN("8/8/4p3/3P4/8/8/8/8 w - - 0 1")
+	s.EnPassantTarget = "e6"
 	assert.False(t, validatePawnMoveWithState(s, move.NewMove("d4", "e6")), "d4 e6")
 
 	s = *NewTestStateFromFEN("8/8/8/8/3Pp3/8/8/8 b - d3 0 1")
@@ -338,6 +340,66 @@ func TestValidateKingMoveWithStateCastlingInvalidBlockingPieces(t *testing.T) {
 	assert.False(t, validateKingMoveWithState(s, move.NewMove("e8", "c8")), "e8 c8")
 }
 
+func TestValidateKingMoveWithStateCastlingAttackedSquares(t *testing.T) {
+	tests := map[string]struct {
+		fen     string
+		castles []move.Move
+		valid   bool
+	}{
+		"out of check": {
+			fen:     "4k3/4r3/8/8/8/8/8/R3K2R w KQ - 0 1",
+			castles: []move.Move{move.NewMove("e1", "g1"), move.NewMove("e1", "c1")},
+		},
+		"through check kingside": {
+			fen:     "4k3/5r2/8/8/8/8/8/R3K2R w KQ - 0 1",
+			castles: []move.Move{move.NewMove("e1", "g1")},
+		},
+		"through check queenside": {
+			fen:     "r3k2r/8/8/8/8/8/8/3RK3 b kq - 0 1",
+			castles: []move.Move{move.NewMove("e8", "c8")},
+		},
+		"into check": {
+			fen:     "4k3/8/8/8/8/8/7p/R3K2R w KQ - 0 1",
+			castles: []move.Move{move.NewMove("e1", "g1")},
+		},
+		"through knight attack": {
+			fen:     "r3k2r/8/8/8/8/8/4n3/R3K2R w KQkq - 0 1",
+			castles: []move.Move{move.NewMove("e1", "g1"), move.NewMove("e1", "c1")},
+		},
+		"attacked by king": {
+			fen:     "8/8/8/8/8/8/5k2/4K2R w K - 0 1",
+			castles: []move.Move{move.NewMove("e1", "g1")},
+		},
+		"rook passes attacked square": {
+			fen:     "1r2k3/8/8/8/8/8/8/R3K3 w Q - 0 1",
+			castles: []move.Move{move.NewMove("e1", "c1")},
+			valid:   true,
+		},
+		"rook attacked": {
+			fen:     "r3k3/8/8/8/8/8/8/R3K3 w Q - 0 1",
+			castles: []move.Move{move.NewMove("e1", "c1")},
+			valid:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := *NewTestStateFromFEN(test.fen)
+			moves := s.GeneratePossibleMoves()
+
+			for _, m := range test.castles {
+				assert.Equal(t, test.valid, validateKingMoveWithState(s, m), m.String())
+
+				if test.valid {
+					assert.Contains(t, moves, m)
+				} else {
+					assert.NotContains(t, moves, m)
+				}
+			}
+		})
+	}
+}
+
 func TestGenerateMovesDoesntChangeState(t *testing.T) {
 	fen := "rnbqkbnr/p1ppp1pp/1p6/5p2/2P5/P7/1P1PPPPP/RNBQKBNR w Kkq f6 0 3"
 	s := *NewTestStateFromFEN(fen)
@@ -374,6 +436,22 @@ func TestGeneratePossibleMoves(t *testing.T) {
 			fen:           "2R1qk2/1Q6/8/8/8/8/8/8 b - - 1 37",
 			possibleMoves: []move.Move{move.NewMove("e8", "c8"), move.NewMove("e8", "d8"), move.NewMove("f8", "g8")},
 		},
+		"promotions": {
+			fen: "1n5k/P7/8/8/8/8/8/7K w - - 0 1",
+			possibleMoves: []move.Move{
+				move.NewPromotionMove("a7", "a8", piece.Bishop),
+				move.NewPromotionMove("a7", "a8", piece.Knight),
+				move.NewPromotionMove("a7", "a8", piece.Queen),
+				move.NewPromotionMove("a7", "a8", piece.Rook),
+				move.NewPromotionMove("a7", "b8", piece.Bishop),
+				move.NewPromotionMove("a7", "b8", piece.Knight),
+				move.NewPromotionMove("a7", "b8", piece.Queen),
+				move.NewPromotionMove("a7", "b8", piece.Rook),
+				move.NewMove("h1", "g1"),
+				move.NewMove("h1", "g2"),
+				move.NewMove("h1", "h2"),
+			},
+		},
 		"only move take queen with king": {
 			fen:           "rnbqkbnr/pp1p1Qp1/7p/2p1p3/4P3/8/PPPP1PPP/RNB1KBNR b KQkq - 0 4",
 			possibleMoves: []move.Move{move.NewMove("e8", "f7")},
@@ -415,10 +493,6 @@ func TestGeneratePossibleMovesNotIn(t *testing.T) {
 			fen:              board.StartingFEN,
 			notPossibleMoves: []move.Move{move.NewMove("g2", "h3")},
 		},
-		"white can't capture own en passant": {
-			fen:              "rnbqkbnr/pppppppp/8/8/3P4/8/PPP1PPPP/RNBQKBNR w KQkq d3 0 1",
-			notPossibleMoves: []move.Move{move.NewMove("c2", "d3")},
-		},
 	}
 
 	for name, test := range tests {
@@ -432,3 +506,34 @@ func TestGeneratePossibleMovesNotIn(t *testing.T) {
 		})
 	}
 }
+
+func TestGeneratePossibleMovesOwnEnPassant(t *testing.T) {
+	// a FEN can't say this, since it's white's own pawn that just moved
+	s := *NewTestStateFromFEN("rnbqkbnr/pppppppp/8/8/3P4/8/PPP1PPPP/RNBQKBNR w KQkq - 0 1")
+	s.EnPassantTarget = "d3"
+	assert.NotContains(t, s.GeneratePossibleMoves(), move.NewMove("c2", "d3"))
+}
+
+func TestGeneratePossibleMovesMatchesMakeUndoGenerator(t *testing.T) {
+	fens := []string{
+		board.StartingFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+		"r3k2r/8/8/8/8/8/4n3/R3K2R w KQkq - 0 1",
+		"r3k2r/8/8/8/8/8/8/3RK3 b kq - 0 1",
+		"r3k2r/p1pNqpb1/bn2pnp1/3P4/1p2P3/2N2Q1p/PPPBBPPP/R3K2R b KQkq - 0 1",
+	}
+
+	for _, fen := range fens {
+		t.Run(fen, func(t *testing.T) {
+			s := *NewTestStateFromFEN(fen)
+			expected := generateLegalMovesByMakeUndo(s)
+			move.SortMoves(expected)
+			assert.Equal(t, expected, s.GeneratePossibleMoves())
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/ethansaxenian/chess/assert"
+	"github.com/ethansaxenian/chess/bitboard"
 	"github.com/ethansaxenian/chess/board"
 	"github.com/ethansaxenian/chess/move"
 	"github.com/ethansaxenian/chess/piece"
@@ -20,6 +21,11 @@ type moveContext struct {
 func getMoveContext(s State, m move.Move) moveContext {
 	var mc moveContext
 
+	if m.IsDrop() {
+		mc.nextEnPassantTarget = noEnPassantTarget
+		return mc
+	}
+
 	isPawn := s.Piece(m.Source).Type() == piece.Pawn
 	sourceColor := s.Piece(m.Source).Color()
 
@@ -27,7 +33,8 @@ func getMoveContext(s State, m move.Move) moveContext {
 		mc.isCapture = true
 	}
 
-	if isPawn && m.Target == s.EnPassantTarget {
+	// a pawn pushed onto the target isn't capturing anything
+	if isPawn && m.Target == s.EnPassantTarget && m.SourceFile() != m.TargetFile() {
 		capturedSquare := board.AddRank(m.Target, int(sourceColor)*-1)
 		mc.isCapture = true
 		mc.enPassantCapture = capturedSquare
@@ -39,8 +46,8 @@ func getMoveContext(s State, m move.Move) moveContext {
 	}
 
 	if isPawn && m.TargetRank() == piece.MaxPawnRank[sourceColor] {
-		p := s.ActivePlayer().ChoosePromotionPiece(m.Target)
-		mc.PromoteTo = p
+		assert.Assert(m.Promotion != piece.Empty, fmt.Sprintf("getMoveContext: promotion piece missing: %s", m))
+		mc.PromoteTo = m.Promotion
 	}
 
 	if isPawn && (m.TargetRank()-m.SourceRank())*int(sourceColor) == 2 {
@@ -50,19 +57,47 @@ func getMoveContext(s State, m move.Move) moveContext {
 		mc.nextEnPassantTarget = noEnPassantTarget
 	}
 
-	if s.Piece(m.Source).Type() == piece.King && m.Source == piece.StartingKingSquares[sourceColor] {
-		switch m.Target {
-		case "g1", "g8":
-			mc.castling = &struct{ side piece.Side }{piece.Kingside}
-		case "c1", "c8":
-			mc.castling = &struct{ side piece.Side }{piece.Queenside}
+	if side, ok := s.castleSide(m); ok {
+		mc.castling = &struct{ side piece.Side }{side}
+		// a chess960 castle is written as the king taking its own rook
+		mc.isCapture = false
+	}
+
+	assert.Assert(
+		mc.enPassantCapture == "" || mc.nextEnPassantTarget == noEnPassantTarget,
+		"a double pawn move cannot also be an en passant capture!",
+	)
+
+	return mc
+}
+
+// castleSide is the side m castles on, if it is a castle. standard castles
+// are written as the king moving two squares, chess960 ones as the king
+// taking its own rook
+func (s State) castleSide(m move.Move) (piece.Side, bool) {
+	if m.IsDrop() {
+		return 0, false
+	}
+
+	king := s.Piece(m.Source)
+	if king.Type() != piece.King {
+		return 0, false
+	}
+
+	color := king.Color()
+	if m.Source != bitboard.SquareName(s.castlingSetup().King(color)) {
+		return 0, false
+	}
+
+	for _, side := range []piece.Side{piece.Kingside, piece.Queenside} {
+		if s.Chess960() && m.Target == s.castlingRook(color, side) && s.Piece(m.Target) == piece.Rook*color {
+			return side, true
 		}
 
-		assert.Assert(
-			mc.enPassantCapture == "" || mc.nextEnPassantTarget == noEnPassantTarget,
-			"a double pawn move cannot also be an en passant capture!",
-		)
+		if !s.Chess960() && m.Target == piece.CastlingSquares[color][side] {
+			return side, true
+		}
 	}
 
-	return mc
+	return 0, false
 }
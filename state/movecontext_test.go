@@ -127,7 +127,7 @@ func TestGetMoveContext(t *testing.T) {
 		},
 		"white promotion": {
 			startingFEN: "8/7P/8/8/8/8/p7/8 w - - 0 1",
-			move:        move.NewMove("h7", "h8"),
+			move:        move.NewPromotionMove("h7", "h8", piece.Queen),
 			expected: moveContext{
 				isCapture:           false,
 				enPassantCapture:    "",
@@ -138,7 +138,7 @@ func TestGetMoveContext(t *testing.T) {
 		},
 		"black promotion": {
 			startingFEN: "8/7P/8/8/8/8/p7/8 b - - 0 1",
-			move:        move.NewMove("a2", "a1"),
+			move:        move.NewPromotionMove("a2", "a1", piece.Queen),
 			expected: moveContext{
 				isCapture:           false,
 				enPassantCapture:    "",
@@ -149,7 +149,7 @@ func TestGetMoveContext(t *testing.T) {
 		},
 		"white promotion + capture": {
 			startingFEN: "6n1/7P/8/8/8/8/p7/1N6 w - - 0 1",
-			move:        move.NewMove("h7", "g8"),
+			move:        move.NewPromotionMove("h7", "g8", piece.Queen),
 			expected: moveContext{
 				isCapture:           true,
 				enPassantCapture:    "",
@@ -160,7 +160,7 @@ func TestGetMoveContext(t *testing.T) {
 		},
 		"black promotion + capture": {
 			startingFEN: "6n1/7P/8/8/8/8/p7/1N6 w - - 0 1",
-			move:        move.NewMove("a2", "b1"),
+			move:        move.NewPromotionMove("a2", "b1", piece.Queen),
 			expected: moveContext{
 				isCapture:           true,
 				enPassantCapture:    "",
@@ -179,3 +179,13 @@ func TestGetMoveContext(t *testing.T) {
 		})
 	}
 }
+
+func TestGetMoveContextPushOntoEnPassantTarget(t *testing.T) {
+	// only a capture onto the target takes a pawn en passant
+	s := NewTestStateFromFEN(board.StartingFEN)
+	s.EnPassantTarget = "e3"
+
+	mc := getMoveContext(*s, move.NewMove("e2", "e3"))
+	assert.False(t, mc.isCapture)
+	assert.Empty(t, mc.enPassantCapture)
+}
@@ -0,0 +1,63 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethansaxenian/chess/move"
+)
+
+func (s *State) Perft(depth int) int {
+	if depth == 0 {
+		return 1
+	}
+
+	validMoves := s.GeneratePossibleMoves()
+	if depth == 1 {
+		return len(validMoves)
+	}
+
+	var nodes int
+	for _, m := range validMoves {
+		s.makeMove(m)
+		nodes += s.Perft(depth - 1)
+		s.undo()
+	}
+
+	return nodes
+}
+
+func (s *State) Divide(depth int) map[move.Move]int {
+	divide := map[move.Move]int{}
+
+	if depth < 1 {
+		return divide
+	}
+
+	for _, m := range s.GeneratePossibleMoves() {
+		s.makeMove(m)
+		divide[m] = s.Perft(depth - 1)
+		s.undo()
+	}
+
+	return divide
+}
+
+// FormatDivide matches the output of stockfish's "go perft" so the two can be diffed
+func FormatDivide(divide map[move.Move]int) string {
+	moves := make([]move.Move, 0, len(divide))
+	for m := range divide {
+		moves = append(moves, m)
+	}
+	move.SortMoves(moves)
+
+	var sb strings.Builder
+	var nodes int
+	for _, m := range moves {
+		fmt.Fprintf(&sb, "%s: %d\n", m, divide[m])
+		nodes += divide[m]
+	}
+	fmt.Fprintf(&sb, "\nNodes searched: %d\n", nodes)
+
+	return sb.String()
+}
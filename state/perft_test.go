@@ -0,0 +1,119 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+// reference counts from https://www.chessprogramming.org/Perft_Results, indexed by depth-1
+var perftPositions = map[string]struct {
+	fen      string
+	nodes    []int
+	maxDepth int
+}{
+	"start position": {
+		fen:      board.StartingFEN,
+		nodes:    []int{20, 400, 8902, 197281},
+		maxDepth: 3,
+	},
+	"kiwipete": {
+		fen:      "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		nodes:    []int{48, 2039, 97862},
+		maxDepth: 2,
+	},
+	"position 3": {
+		fen:      "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		nodes:    []int{14, 191, 2812, 43238},
+		maxDepth: 3,
+	},
+	"position 4": {
+		fen:      "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		nodes:    []int{6, 264, 9467},
+		maxDepth: 2,
+	},
+	"position 5": {
+		fen:      "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		nodes:    []int{44, 1486, 62379},
+		maxDepth: 2,
+	},
+	"position 6": {
+		fen:      "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+		nodes:    []int{46, 2079, 89890},
+		maxDepth: 2,
+	},
+}
+
+func TestPerft(t *testing.T) {
+	for name, test := range perftPositions {
+		for depth := 1; depth <= test.maxDepth; depth++ {
+			t.Run(fmt.Sprintf("%s depth %d", name, depth), func(t *testing.T) {
+				s := NewTestStateFromFEN(test.fen)
+				assert.Equal(t, test.nodes[depth-1], s.Perft(depth))
+				assert.Equal(t, test.fen, s.FEN())
+			})
+		}
+	}
+}
+
+func TestDivide(t *testing.T) {
+	s := NewTestStateFromFEN(board.StartingFEN)
+	divide := s.Divide(2)
+
+	assert.Len(t, divide, 20)
+	assert.Equal(t, 20, divide[move.NewMove("e2", "e4")])
+	assert.Equal(t, 20, divide[move.NewMove("g1", "f3")])
+}
+
+func TestFormatDivide(t *testing.T) {
+	divide := map[move.Move]int{
+		move.NewMove("e2", "e4"):                       20,
+		move.NewMove("a2", "a3"):                       20,
+		move.NewPromotionMove("b7", "b8", piece.Queen): 3,
+	}
+
+	expected := "a2a3: 20\nb7b8q: 3\ne2e4: 20\n\nNodes searched: 43\n"
+	assert.Equal(t, expected, FormatDivide(divide))
+}
+
+// legacyPerft counts nodes with the make/undo generator, for comparison in benchmarks
+func legacyPerft(s *State, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+
+	var nodes int
+	for _, m := range generateLegalMovesByMakeUndo(*s) {
+		s.MakeMove(m)
+		nodes += legacyPerft(s, depth-1)
+		s.Undo()
+	}
+
+	return nodes
+}
+
+func BenchmarkPerft(b *testing.B) {
+	s := NewTestStateFromFEN(board.StartingFEN)
+	for i := 0; i < b.N; i++ {
+		s.Perft(2)
+	}
+}
+
+func BenchmarkPerftLegacy(b *testing.B) {
+	s := NewTestStateFromFEN(board.StartingFEN)
+	for i := 0; i < b.N; i++ {
+		legacyPerft(s, 2)
+	}
+}
+
+func BenchmarkPerftBitboard(b *testing.B) {
+	s := NewTestStateFromFEN(board.StartingFEN)
+	pos := s.Position()
+	for i := 0; i < b.N; i++ {
+		pos.Perft(2)
+	}
+}
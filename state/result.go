@@ -0,0 +1,121 @@
+package state
+
+import (
+	"fmt"
+
+	"github.com/ethansaxenian/chess/piece"
+)
+
+type Termination int
+
+const (
+	Unterminated Termination = iota
+	Checkmate
+	Stalemate
+	Resignation
+	Timeout
+	ThreefoldRepetition
+	FivefoldRepetition
+	Agreement
+	InsufficientMaterial
+	DeadPosition
+	FiftyMoveRule
+	SeventyFiveMoveRule
+	Abandonment
+	RulesInfraction
+	ThreeChecks
+	KingInTheCenter
+	Explosion
+	AllPiecesLost
+)
+
+func (t Termination) String() string {
+	switch t {
+	case Checkmate:
+		return "checkmate"
+	case Stalemate:
+		return "stalemate"
+	case Resignation:
+		return "resignation"
+	case Timeout:
+		return "timeout"
+	case ThreefoldRepetition:
+		return "threefold repetition"
+	case FivefoldRepetition:
+		return "fivefold repetition"
+	case Agreement:
+		return "agreement"
+	case InsufficientMaterial:
+		return "insufficient material"
+	case DeadPosition:
+		return "dead position"
+	case FiftyMoveRule:
+		return "the fifty-move rule"
+	case SeventyFiveMoveRule:
+		return "the seventy-five-move rule"
+	case Abandonment:
+		return "abandonment"
+	case RulesInfraction:
+		return "rules infraction"
+	case ThreeChecks:
+		return "three checks"
+	case KingInTheCenter:
+		return "king in the center"
+	case Explosion:
+		return "explosion"
+	case AllPiecesLost:
+		return "losing all pieces"
+	default:
+		return "unterminated"
+	}
+}
+
+// GameResult is how a game ended. the zero value is a game still in progress,
+// and Winner is piece.Empty for draws
+type GameResult struct {
+	Winner      piece.Piece
+	Termination Termination
+}
+
+func Win(winner piece.Piece, termination Termination) GameResult {
+	return GameResult{Winner: winner.Color(), Termination: termination}
+}
+
+func Draw(termination Termination) GameResult {
+	return GameResult{Winner: piece.Empty, Termination: termination}
+}
+
+func (r GameResult) Over() bool {
+	return r.Termination != Unterminated
+}
+
+func (r GameResult) IsDraw() bool {
+	return r.Over() && r.Winner == piece.Empty
+}
+
+// PGN returns the result token used in PGN tags and movetext
+func (r GameResult) PGN() string {
+	switch {
+	case !r.Over():
+		return "*"
+	case r.Winner == piece.White:
+		return "1-0"
+	case r.Winner == piece.Black:
+		return "0-1"
+	default:
+		return "1/2-1/2"
+	}
+}
+
+func (r GameResult) String() string {
+	switch {
+	case !r.Over():
+		return "game in progress"
+	case r.Winner == piece.White:
+		return fmt.Sprintf("white wins by %s", r.Termination)
+	case r.Winner == piece.Black:
+		return fmt.Sprintf("black wins by %s", r.Termination)
+	default:
+		return fmt.Sprintf("draw by %s", r.Termination)
+	}
+}
@@ -0,0 +1,92 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameResult(t *testing.T) {
+	tests := map[string]struct {
+		result GameResult
+		over   bool
+		draw   bool
+		pgn    string
+		str    string
+	}{
+		"in progress": {
+			result: GameResult{},
+			pgn:    "*",
+			str:    "game in progress",
+		},
+		"white checkmates": {
+			result: Win(piece.White, Checkmate),
+			over:   true,
+			pgn:    "1-0",
+			str:    "white wins by checkmate",
+		},
+		"black wins on time": {
+			result: Win(piece.Black, Timeout),
+			over:   true,
+			pgn:    "0-1",
+			str:    "black wins by timeout",
+		},
+		"winner is normalized to a color": {
+			result: Win(piece.Queen*piece.Black, Resignation),
+			over:   true,
+			pgn:    "0-1",
+			str:    "black wins by resignation",
+		},
+		"draw by agreement": {
+			result: Draw(Agreement),
+			over:   true,
+			draw:   true,
+			pgn:    "1/2-1/2",
+			str:    "draw by agreement",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.over, test.result.Over())
+			assert.Equal(t, test.draw, test.result.IsDraw())
+			assert.Equal(t, test.pgn, test.result.PGN())
+			assert.Equal(t, test.str, test.result.String())
+		})
+	}
+}
+
+func TestCheckGameOverCheckmate(t *testing.T) {
+	s := NewTestStateFromFEN("R6k/6pp/8/8/8/8/8/7K b - - 0 1")
+	res, over := s.CheckGameOver()
+	assert.True(t, over)
+	assert.Equal(t, GameResult{Winner: piece.White, Termination: Checkmate}, res)
+
+	s = NewTestStateFromFEN("7k/5Q2/6K1/8/8/8/8/8 b - - 0 1")
+	res, over = s.CheckGameOver()
+	assert.True(t, over)
+	assert.Equal(t, Draw(Stalemate), res)
+}
+
+func TestEndGame(t *testing.T) {
+	s := NewTestStateFromFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	s.EndGame(Win(piece.Black, Resignation))
+
+	res, over := s.CheckGameOver()
+	assert.True(t, over)
+	assert.Equal(t, Win(piece.Black, Resignation), res)
+}
+
+func TestEndGameSurvivesSAN(t *testing.T) {
+	s := NewTestStateFromFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	s.EndGame(Win(piece.Black, Resignation))
+
+	// SAN plays the move and takes it back to look for check
+	s.SAN(move.NewMove("e2", "e4"))
+
+	res, over := s.CheckGameOver()
+	assert.True(t, over)
+	assert.Equal(t, Win(piece.Black, Resignation), res)
+}
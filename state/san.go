@@ -0,0 +1,220 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+)
+
+var (
+	ErrInvalidSAN   = errors.New("invalid SAN")
+	ErrIllegalSAN   = errors.New("illegal move")
+	ErrAmbiguousSAN = errors.New("ambiguous move")
+)
+
+var sanPattern = regexp.MustCompile(`^([NBRQK])?([a-h])?([1-8])?(x)?([a-h][1-8])(?:=?([NBRQK]))?$`)
+
+// crazyhouse drops, where the P is optional for pawns
+var sanDropPattern = regexp.MustCompile(`^([PNBRQ])?@([a-h][1-8])$`)
+
+var sanPieces = map[byte]piece.Piece{
+	'P': piece.Pawn,
+	'N': piece.Knight,
+	'B': piece.Bishop,
+	'R': piece.Rook,
+	'Q': piece.Queen,
+	'K': piece.King,
+}
+
+func sanPieceLetter(p piece.Piece) string {
+	return strings.ToUpper(p.Type().FEN())
+}
+
+func (s State) isCastle(m move.Move) bool {
+	_, ok := s.castleSide(m)
+	return ok
+}
+
+func (s State) isCaptureMove(m move.Move) bool {
+	if s.Piece(m.Target) != piece.Empty {
+		return true
+	}
+
+	return s.Piece(m.Source).Type() == piece.Pawn && m.Target == s.EnPassantTarget && m.SourceFile() != m.TargetFile()
+}
+
+func (s *State) SAN(m move.Move) string {
+	p := s.movedPiece(m)
+
+	var san string
+
+	side, castle := s.castleSide(m)
+	switch {
+	case m.IsDrop():
+		san = sanPieceLetter(m.Drop) + "@" + m.Target
+	case castle && side == piece.Kingside:
+		san = "O-O"
+	case castle:
+		san = "O-O-O"
+	case p.Type() == piece.Pawn:
+		if s.isCaptureMove(m) {
+			san = string(m.SourceFile()) + "x"
+		}
+		san += m.Target
+	default:
+		san = sanPieceLetter(p) + s.disambiguation(m)
+		if s.isCaptureMove(m) {
+			san += "x"
+		}
+		san += m.Target
+	}
+
+	if m.Promotion != piece.Empty {
+		san += "=" + sanPieceLetter(m.Promotion)
+	}
+
+	s.makeMove(m)
+
+	if s.IsCheck() {
+		if len(s.GeneratePossibleMoves()) == 0 {
+			san += "#"
+		} else {
+			san += "+"
+		}
+	}
+
+	s.undo()
+
+	return san
+}
+
+func (s State) disambiguation(m move.Move) string {
+	p := s.Piece(m.Source)
+
+	var ambiguous, sameFile, sameRank bool
+	for _, other := range s.GeneratePossibleMoves() {
+		if other.Target != m.Target || other.IsDrop() || other.Source == m.Source || s.Piece(other.Source) != p {
+			continue
+		}
+
+		ambiguous = true
+		if other.SourceFile() == m.SourceFile() {
+			sameFile = true
+		}
+		if other.SourceRank() == m.SourceRank() {
+			sameRank = true
+		}
+	}
+
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameFile:
+		return string(m.SourceFile())
+	case !sameRank:
+		return m.Source[1:]
+	default:
+		return m.Source
+	}
+}
+
+func (s State) ParseSAN(san string) (move.Move, error) {
+	trimmed := strings.TrimRight(san, "+#!?")
+
+	validMoves := s.GeneratePossibleMoves()
+
+	switch trimmed {
+	case "O-O", "0-0", "O-O-O", "0-0-0":
+		for _, m := range validMoves {
+			side, ok := s.castleSide(m)
+			if !ok {
+				continue
+			}
+
+			if (side == piece.Kingside) == (len(trimmed) == 3) {
+				return m, nil
+			}
+		}
+
+		return move.Move{}, fmt.Errorf("%w: %s", ErrIllegalSAN, san)
+	}
+
+	if groups := sanDropPattern.FindStringSubmatch(trimmed); groups != nil {
+		drop := move.NewDropMove(piece.Pawn, groups[2])
+		if groups[1] != "" {
+			drop.Drop = sanPieces[groups[1][0]]
+		}
+
+		if !slices.Contains(validMoves, drop) {
+			return move.Move{}, fmt.Errorf("%w: %s", ErrIllegalSAN, san)
+		}
+
+		return drop, nil
+	}
+
+	groups := sanPattern.FindStringSubmatch(trimmed)
+	if groups == nil {
+		return move.Move{}, fmt.Errorf("%w: %s", ErrInvalidSAN, san)
+	}
+
+	pieceType := piece.Pawn
+	if groups[1] != "" {
+		pieceType = sanPieces[groups[1][0]]
+	}
+	fromFile, fromRank, target, promotion := groups[2], groups[3], groups[5], groups[6]
+
+	if promotion != "" && pieceType != piece.Pawn {
+		return move.Move{}, fmt.Errorf("%w: %s", ErrInvalidSAN, san)
+	}
+
+	var candidates []move.Move
+	for _, m := range validMoves {
+		if m.IsDrop() {
+			continue
+		}
+
+		p := s.Piece(m.Source)
+		switch {
+		case p.Type() != pieceType, m.Target != target:
+			continue
+		case fromFile != "" && string(m.SourceFile()) != fromFile:
+			continue
+		case fromRank != "" && m.Source[1:] != fromRank:
+			continue
+		case pieceType == piece.Pawn && fromFile == "" && m.SourceFile() != m.TargetFile():
+			continue
+		case s.isCastle(m):
+			continue
+		case promotion != "" && m.Promotion != sanPieces[promotion[0]]:
+			continue
+		}
+
+		candidates = append(candidates, m)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return move.Move{}, fmt.Errorf("%w: %s", ErrIllegalSAN, san)
+	case 1:
+		return candidates[0], nil
+	default:
+		return move.Move{}, fmt.Errorf("%w: %s", ErrAmbiguousSAN, san)
+	}
+}
+
+func (s State) SANMoves() []string {
+	replay := s.StartingPosition()
+
+	sans := make([]string, 0, len(s.Moves))
+	for _, m := range s.Moves {
+		sans = append(sans, replay.SAN(m))
+		replay.makeMove(m)
+	}
+
+	return sans
+}
@@ -0,0 +1,142 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSAN(t *testing.T) {
+	tests := map[string]struct {
+		fen  string
+		move move.Move
+		san  string
+	}{
+		"pawn push": {
+			fen:  board.StartingFEN,
+			move: move.NewMove("e2", "e4"),
+			san:  "e4",
+		},
+		"knight": {
+			fen:  board.StartingFEN,
+			move: move.NewMove("g1", "f3"),
+			san:  "Nf3",
+		},
+		"pawn capture": {
+			fen:  "rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 2",
+			move: move.NewMove("e4", "d5"),
+			san:  "exd5",
+		},
+		"en passant": {
+			fen:  "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1",
+			move: move.NewMove("e5", "d6"),
+			san:  "exd6",
+		},
+		"kingside castle": {
+			fen:  "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			move: move.NewMove("e1", "g1"),
+			san:  "O-O",
+		},
+		"queenside castle": {
+			fen:  "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1",
+			move: move.NewMove("e8", "c8"),
+			san:  "O-O-O",
+		},
+		"promotion": {
+			fen:  "8/4P3/8/8/8/8/k7/4K3 w - - 0 1",
+			move: move.NewPromotionMove("e7", "e8", piece.Queen),
+			san:  "e8=Q",
+		},
+		"underpromotion with check": {
+			fen:  "8/4P1k1/8/8/8/8/8/4K3 w - - 0 1",
+			move: move.NewPromotionMove("e7", "e8", piece.Knight),
+			san:  "e8=N+",
+		},
+		"file disambiguation with mate": {
+			fen:  "2rkr3/2p1p3/8/8/8/K7/8/R6R w - - 0 1",
+			move: move.NewMove("a1", "d1"),
+			san:  "Rad1#",
+		},
+		"capture disambiguation with mate": {
+			fen:  "2rkr3/2p1p3/8/8/8/K7/8/R2n3R w - - 0 1",
+			move: move.NewMove("a1", "d1"),
+			san:  "Raxd1#",
+		},
+		"rank disambiguation": {
+			fen:  "4k3/8/8/R7/8/8/8/R3K3 w - - 0 1",
+			move: move.NewMove("a1", "a3"),
+			san:  "R1a3",
+		},
+		"full disambiguation": {
+			fen:  "4k3/8/8/8/Q1Q5/8/Q7/4K3 w - - 0 1",
+			move: move.NewMove("a4", "b3"),
+			san:  "Qa4b3",
+		},
+		"check": {
+			fen:  "4k3/8/8/8/8/8/8/R3K3 w - - 0 1",
+			move: move.NewMove("a1", "a8"),
+			san:  "Ra8+",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewTestStateFromFEN(test.fen)
+			assert.Equal(t, test.san, s.SAN(test.move))
+			assert.Equal(t, test.fen, s.FEN())
+
+			parsed, err := s.ParseSAN(test.san)
+			assert.NoError(t, err)
+			assert.Equal(t, test.move, parsed)
+		})
+	}
+}
+
+func TestParseSANErrors(t *testing.T) {
+	s := NewTestStateFromFEN("2rkr3/2p1p3/8/8/8/K7/8/R6R w - - 0 1")
+
+	_, err := s.ParseSAN("Rd1")
+	assert.ErrorIs(t, err, ErrAmbiguousSAN)
+
+	_, err = s.ParseSAN("Nf3")
+	assert.ErrorIs(t, err, ErrIllegalSAN)
+
+	_, err = s.ParseSAN("O-O")
+	assert.ErrorIs(t, err, ErrIllegalSAN)
+
+	_, err = s.ParseSAN("hello")
+	assert.ErrorIs(t, err, ErrInvalidSAN)
+}
+
+func TestParseSANVariants(t *testing.T) {
+	s := NewTestStateFromFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+
+	m, err := s.ParseSAN("0-0-0")
+	assert.NoError(t, err)
+	assert.Equal(t, move.NewMove("e1", "c1"), m)
+
+	m, err = s.ParseSAN("Ra1d1+!?")
+	assert.NoError(t, err)
+	assert.Equal(t, move.NewMove("a1", "d1"), m)
+}
+
+func TestGenerateMovesDoesntChangeCastlingRights(t *testing.T) {
+	fen := "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"
+	s := NewTestStateFromFEN(fen)
+	s.GeneratePossibleMoves()
+	assert.Equal(t, fen, s.FEN())
+}
+
+func TestParseSANPromotion(t *testing.T) {
+	s := NewTestStateFromFEN("3n4/4P3/8/8/8/8/k7/4K3 w - - 0 1")
+
+	m, err := s.ParseSAN("exd8=R")
+	assert.NoError(t, err)
+	assert.Equal(t, move.NewPromotionMove("e7", "d8", piece.Rook), m)
+
+	_, err = s.ParseSAN("e8")
+	assert.ErrorIs(t, err, ErrAmbiguousSAN)
+}
@@ -1,12 +1,17 @@
 package state
 
 import (
+	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/ethansaxenian/chess/assert"
+	"github.com/ethansaxenian/chess/bitboard"
 	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/clock"
 	"github.com/ethansaxenian/chess/move"
 	"github.com/ethansaxenian/chess/piece"
 	"github.com/ethansaxenian/chess/player"
@@ -14,51 +19,43 @@ import (
 
 const noEnPassantTarget = "-"
 
-type gameOverState int
-
-const (
-	no gameOverState = iota
-	whiteWin
-	blackWin
-	stalemate
-	draw
-)
-
-func (g gameOverState) String() string {
-	switch g {
-	case whiteWin:
-		return "white wins!"
-	case blackWin:
-		return "black wins!"
-	case stalemate:
-		return "stalemate"
-	case draw:
-		return "draw"
-	default:
-		assert.Raise("The game is not over?")
-		return ""
-	}
-}
-
 type State struct {
 	Players         map[piece.Piece]player.Player
 	Castling        map[piece.Piece]map[piece.Side]bool
 	EnPassantTarget string
 	Moves           []move.Move
-	fens            []string
+	startingFEN     string
+	history         []undoRecord
+	positions       []uint64
 	Board           board.Chessboard
 	nextBoard       board.Chessboard
 	ActiveColor     piece.Piece
 	HalfmoveClock   int
 	FullmoveNumber  int
-	headless        bool
+	hash            uint64
+	// DeadPositions also ends games in blocked pawn structures that neither
+	// side can break, which is a heuristic rather than a full search
+	DeadPositions bool
+	// Clock is pressed by MakeMove, if there is one
+	Clock    *clock.Clock
+	result   GameResult
+	headless bool
+	// where the kings and rooks castle from, nil for standard chess
+	setup *bitboard.CastlingSetup
+	// Pockets hold the pieces each side has captured and can drop, in crazyhouse
+	Pockets  map[piece.Piece]piece.Pocket
+	promoted bitboard.Bitboard
+	// nil for standard chess
+	variant Variant
+	// how many times each side has given check, in three-check
+	checks map[piece.Piece]int
 }
 
 func StartingState(white, black player.Player) *State {
 	return StartingStateFromFEN(board.StartingFEN, white, black)
 }
 
-func StartingStateFromFEN(fen string, white, black player.Player) *State {
+func StartingStateFromFEN(fen string, white, black player.Player, opts ...func(*FENOptions)) *State {
 	s := &State{
 		Players: map[piece.Piece]player.Player{
 			piece.White: white,
@@ -66,69 +63,48 @@ func StartingStateFromFEN(fen string, white, black player.Player) *State {
 		},
 	}
 
-	s.LoadFEN(fen)
+	var o FENOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	err := s.loadFEN(fen, o)
+	assert.ErrIsNil(err, fmt.Sprint(err))
 
 	return s
 }
 
+// LoadFEN keeps playing the same variant, and chess960 if the state already was
 func (s *State) LoadFEN(fen string) {
-	fenFields := strings.Fields(fen)
-
-	s.Board = board.LoadFEN(fenFields[0])
-	s.nextBoard = s.Board
+	err := s.loadFEN(fen, s.fenOptions())
+	assert.ErrIsNil(err, fmt.Sprint(err))
+}
 
-	var activeColor piece.Piece
-	switch fenFields[1] {
-	case "w":
-		activeColor = piece.White
-	case "b":
-		activeColor = piece.Black
-	default:
-		assert.Raise(fmt.Sprintf("invalid FEN active color: %s", fen))
-	}
-	s.ActiveColor = activeColor
-
-	whiteCastling := map[piece.Side]bool{}
-	blackCastling := map[piece.Side]bool{}
-	for _, char := range fenFields[2] {
-		switch char {
-		case 'K':
-			whiteCastling[piece.Kingside] = true
-		case 'Q':
-			whiteCastling[piece.Queenside] = true
-		case 'k':
-			blackCastling[piece.Kingside] = true
-		case 'q':
-			blackCastling[piece.Queenside] = true
-		case '-':
-			continue
-		default:
-			assert.Raise(fmt.Sprintf("invalid FEN castling rights: %s", fen))
-		}
-	}
-	s.Castling = map[piece.Piece]map[piece.Side]bool{
-		piece.White: whiteCastling,
-		piece.Black: blackCastling,
-	}
+// fenOptions reads FENs the way this state's game is played
+func (s State) fenOptions() FENOptions {
+	return FENOptions{Chess960: s.Chess960(), Variant: s.variant}
+}
 
-	s.EnPassantTarget = fenFields[3]
+func (s State) Chess960() bool {
+	return s.castlingSetup().Chess960()
+}
 
-	halfmoveClock, err := strconv.Atoi(fenFields[4])
-	assert.ErrIsNil(err, fmt.Sprintf("invalid FEN halfmove clock: %s", fen))
-	s.HalfmoveClock = halfmoveClock
+func (s State) castlingSetup() *bitboard.CastlingSetup {
+	if s.setup == nil {
+		return bitboard.StandardCastling
+	}
 
-	fullmoveNumber, err := strconv.Atoi(fenFields[5])
-	assert.ErrIsNil(err, fmt.Sprintf("invalid FEN fullmove clock: %s", fen))
-	s.FullmoveNumber = fullmoveNumber
+	return s.setup
+}
 
-	s.fens = append(s.fens, fen)
-	assert.AddContext("FEN", s.FEN())
-	assert.AddContext("moves", s.Moves)
+// castlingRook is the square color's rook castles from on one side
+func (s State) castlingRook(color piece.Piece, side piece.Side) string {
+	return bitboard.SquareName(s.castlingSetup().Rook(color, side))
 }
 
 func (s State) FEN() string {
 	var fen []string
-	fen = append(fen, s.Board.FEN())
+	fen = append(fen, s.placementField())
 
 	if s.ActiveColor == piece.White {
 		fen = append(fen, "w")
@@ -136,31 +112,30 @@ func (s State) FEN() string {
 		fen = append(fen, "b")
 	}
 
-	var castling string
-	if s.Castling[piece.White][piece.Kingside] {
-		castling += "K"
-	}
-	if s.Castling[piece.White][piece.Queenside] {
-		castling += "Q"
-	}
-	if s.Castling[piece.Black][piece.Kingside] {
-		castling += "k"
-	}
-	if s.Castling[piece.Black][piece.Queenside] {
-		castling += "q"
-	}
-	if castling == "" {
-		castling = "-"
-	}
-
-	fen = append(fen, castling)
+	fen = append(fen, s.castlingField(false))
 	fen = append(fen, s.EnPassantTarget)
+	if s.Variant() == ThreeCheck {
+		fen = append(fen, s.checksField())
+	}
 	fen = append(fen, strconv.Itoa(s.HalfmoveClock))
 	fen = append(fen, strconv.Itoa(s.FullmoveNumber))
 
 	return strings.Join(fen, " ")
 }
 
+func (s State) StartingFEN() string {
+	return s.startingFEN
+}
+
+// StartingPosition is the position the game started from, played by the same
+// rules, without players
+func (s State) StartingPosition() *State {
+	start := StartingStateFromFEN(s.StartingFEN(), nil, nil, func(o *FENOptions) { *o = s.fenOptions() })
+	start.headless = true
+
+	return start
+}
+
 func (s State) Piece(square string) piece.Piece {
 	return s.Board.Square(square)
 }
@@ -174,6 +149,20 @@ func (s State) ActivePlayer() player.Player {
 	return s.Players[s.ActiveColor]
 }
 
+// ActivePlayerMove asks the player whose turn it is for one of validMoves
+func (s State) ActivePlayerMove(ctx context.Context, validMoves []move.Move) (move.Move, error) {
+	m, err := s.ActivePlayer().GetMove(ctx, gameView{s, validMoves})
+	if err != nil {
+		return move.Move{}, err
+	}
+
+	if !slices.Contains(validMoves, m) {
+		return move.Move{}, fmt.Errorf("%s chose an illegal move: %s", s.ActivePlayerRepr(), m)
+	}
+
+	return m, nil
+}
+
 func (s State) PlayerRepr(color piece.Piece) string {
 	var colorStr string
 	switch color {
@@ -202,24 +191,46 @@ func (s State) Print() {
 	clearScreen()
 	s.Board.Print()
 	fmt.Println(s)
+
+	if s.Clock != nil {
+		fmt.Printf("white %s  black %s\n", clock.Format(s.Clock.Remaining(piece.White)), clock.Format(s.Clock.Remaining(piece.Black)))
+	}
+}
+
+// StartClock times the rest of the game with c, starting with the side to move
+func (s *State) StartClock(c *clock.Clock) {
+	s.Clock = c
+	c.Start(s.ActiveColor)
+}
+
+// TurnContext is ctx with the side to move's flag fall as its deadline
+func (s State) TurnContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.Clock != nil {
+		if deadline, ok := s.Clock.Deadline(); ok {
+			return context.WithDeadline(ctx, deadline)
+		}
+	}
+
+	return context.WithCancel(ctx)
 }
 
 func (s *State) handleEnPassantAvailable(_ move.Move, mc moveContext) {
 	s.EnPassantTarget = mc.nextEnPassantTarget
-	assert.AddContext("FEN", s.FEN())
 }
 
 func (s *State) handleEnPassantCapture(_ move.Move, mc moveContext) {
 	if mc.enPassantCapture != "" {
+		s.hashSquare(mc.enPassantCapture, s.nextBoard.Square(mc.enPassantCapture))
 		s.nextBoard[board.SquareToIndex(mc.enPassantCapture)] = piece.Empty
-		assert.AddContext("FEN", s.FEN())
 	}
 }
 
 func (s *State) handlePromotion(m move.Move, mc moveContext) {
 	if mc.PromoteTo != piece.Empty {
-		s.nextBoard[board.SquareToIndex(m.Target)] = mc.PromoteTo * s.Piece(m.Source).Color()
-		assert.AddContext("FEN", s.FEN())
+		promoted := mc.PromoteTo * s.Piece(m.Source).Color()
+		s.hashSquare(m.Target, s.nextBoard.Square(m.Target))
+		s.hashSquare(m.Target, promoted)
+		s.nextBoard[board.SquareToIndex(m.Target)] = promoted
 	}
 }
 
@@ -227,12 +238,14 @@ func (s *State) handleUpdateCastlingRights(m move.Move, _ moveContext) {
 	assert.AddContext("move", m)
 
 	// rook movement
-	for color, startingSquares := range piece.StartingRookSquares {
+	for _, color := range piece.AllColors {
 		castlingRights, ok := s.Castling[color]
 		assert.Assert(ok, fmt.Sprintf("invalid castling rights: color %d not found: %v", color, s.Castling))
 
-		for side, square := range startingSquares {
-			if s.nextBoard.Square(square) != piece.Rook*color {
+		// the king may have gone without moving, in atomic
+		king := bitboard.SquareName(s.castlingSetup().King(color))
+		for _, side := range []piece.Side{piece.Kingside, piece.Queenside} {
+			if s.nextBoard.Square(s.castlingRook(color, side)) != piece.Rook*color || s.nextBoard.Square(king) != piece.King*color {
 				castlingRights[side] = false
 			}
 		}
@@ -241,37 +254,112 @@ func (s *State) handleUpdateCastlingRights(m move.Move, _ moveContext) {
 	}
 
 	// king movement
-	if s.Piece(m.Source).Type() == piece.King {
+	if !m.IsDrop() && s.Piece(m.Source).Type() == piece.King {
 		color := s.Piece(m.Source).Color()
 		s.Castling[color][piece.Kingside] = false
 		s.Castling[color][piece.Queenside] = false
 	}
 }
 
+// handleCastle moves the king and rook together. in chess960 they can land on
+// each other's squares, so both leave the board first
 func (s *State) handleCastle(m move.Move, mc moveContext) {
-	// castling occurs
-	if mc.castling != nil {
-		color := s.Piece(m.Source).Color()
-		rookSource := piece.StartingRookSquares[color][mc.castling.side]
-		rookTarget := piece.RookCastlingSquares[color][mc.castling.side]
-		assert.Assert(s.Piece(rookSource) == piece.Rook*color, "no rook found when castling")
-		s.nextBoard.MakeMove(move.NewMove(rookSource, rookTarget))
+	color := s.Piece(m.Source).Color()
+	king, rook := piece.King*color, piece.Rook*color
+	kingTarget := piece.CastlingSquares[color][mc.castling.side]
+	rookSource := s.castlingRook(color, mc.castling.side)
+	rookTarget := piece.RookCastlingSquares[color][mc.castling.side]
+	assert.Assert(s.Piece(rookSource) == rook, "no rook found when castling")
+
+	s.hashSquare(m.Source, king)
+	s.hashSquare(rookSource, rook)
+	s.hashSquare(kingTarget, king)
+	s.hashSquare(rookTarget, rook)
+
+	s.nextBoard[board.SquareToIndex(m.Source)] = piece.Empty
+	s.nextBoard[board.SquareToIndex(rookSource)] = piece.Empty
+	s.nextBoard[board.SquareToIndex(kingTarget)] = king
+	s.nextBoard[board.SquareToIndex(rookTarget)] = rook
+}
+
+func copyCastlingRights(castling map[piece.Piece]map[piece.Side]bool) map[piece.Piece]map[piece.Side]bool {
+	return map[piece.Piece]map[piece.Side]bool{
+		piece.White: maps.Clone(castling[piece.White]),
+		piece.Black: maps.Clone(castling[piece.Black]),
 	}
 }
 
+// MakeMove plays m and presses the clock
 func (s *State) MakeMove(m move.Move) {
-	assert.AddContext("FEN", s.FEN())
+	s.makeMove(m)
+
+	if s.Clock != nil {
+		s.Clock.Press()
+	}
+}
+
+// makeMove only changes the position, for trying moves out
+func (s *State) makeMove(m move.Move) {
+	assert.AddContext("FEN", fenContext{s})
 	assert.AddContext("moves", s.Moves)
 	assert.AddContext("move", m)
 
 	mc := getMoveContext(*s, m)
 
-	var isPawnMove = s.Piece(m.Source).Type() == piece.Pawn
+	r := undoRecord{
+		move:            m,
+		moved:           s.movedPiece(m),
+		captured:        s.Piece(m.Target),
+		capturedSquare:  m.Target,
+		castle:          mc.castling,
+		castling:        s.Castling,
+		enPassantTarget: s.EnPassantTarget,
+		halfmoveClock:   s.HalfmoveClock,
+		fullmoveNumber:  s.FullmoveNumber,
+		hash:            s.hash,
+		pockets:         s.Pockets,
+		promoted:        s.promoted,
+		checks:          s.checks,
+		result:          s.result,
+	}
+	if mc.enPassantCapture != "" {
+		r.captured = s.Piece(mc.enPassantCapture)
+		r.capturedSquare = mc.enPassantCapture
+	}
+	// a chess960 castle is written as the king taking its own rook
+	if mc.castling != nil {
+		r.captured = piece.Empty
+	}
+
+	// the castling maps may be shared with copies of this state, and the old
+	// ones are kept for Undo
+	s.Castling = copyCastlingRights(s.Castling)
+	s.Pockets = maps.Clone(s.Pockets)
 
-	s.nextBoard.MakeMove(m)
-	s.handleEnPassantCapture(m, mc)
-	s.handleCastle(m, mc)
-	s.handlePromotion(m, mc)
+	var isPawnMove = r.moved.Type() == piece.Pawn
+
+	// recorded now, so the variant can add the pieces it removes
+	s.history = append(s.history, r)
+	s.hash ^= s.stateKey()
+
+	s.handlePocketCapture(m, mc)
+
+	switch {
+	case m.IsDrop():
+		s.handleDrop(m, mc)
+	case mc.castling != nil:
+		s.handleCastle(m, mc)
+	default:
+		s.hashSquare(m.Source, s.Piece(m.Source))
+		s.hashSquare(m.Target, s.Piece(m.Target))
+		s.hashSquare(m.Target, s.Piece(m.Source))
+
+		s.nextBoard.MakeMove(m)
+		s.handleEnPassantCapture(m, mc)
+		s.handlePromotion(m, mc)
+	}
+
+	s.Variant().AfterMove(s, m, mc.isCapture)
 	s.handleEnPassantAvailable(m, mc)
 	s.handleUpdateCastlingRights(m, mc)
 	s.Board = s.nextBoard
@@ -288,95 +376,203 @@ func (s *State) MakeMove(m move.Move) {
 	}
 
 	s.ActiveColor *= -1
-	s.fens = append(s.fens, s.FEN())
-	assert.AddContext("FEN", s.FEN())
+	s.hash ^= s.stateKey()
+
+	s.positions = append(s.positions, s.positionKey())
 	assert.AddContext("moves", s.Moves)
 	assert.DeleteContext("move")
 }
 
-func (s *State) Undo() {
-	numFens := len(s.fens)
-
-	assert.Assert(numFens > 1, fmt.Sprintf("cannot undo move? there are %d fens", len(s.fens)))
+// undoRecord holds what MakeMove can't work out backwards from the move alone
+type undoRecord struct {
+	move            move.Move
+	moved           piece.Piece
+	captured        piece.Piece
+	capturedSquare  string
+	castle          *struct{ side piece.Side }
+	castling        map[piece.Piece]map[piece.Side]bool
+	enPassantTarget string
+	halfmoveClock   int
+	fullmoveNumber  int
+	hash            uint64
+	pockets         map[piece.Piece]piece.Pocket
+	promoted        bitboard.Bitboard
+	checks          map[piece.Piece]int
+	// a result EndGame set, which making and undoing a move keeps
+	result GameResult
+	// pieces the variant took off the board after the move
+	removed map[string]piece.Piece
+}
 
-	assert.AddContext("last recorded FEN", s.fens[numFens-1])
-	assert.AddContext("current FEN", s.FEN())
-	assert.Assert(s.fens[numFens-1] == s.FEN(), "Undo: FEN mismatch")
-	assert.DeleteContext("last recorded FEN")
-	assert.DeleteContext("current FEN")
+// removePiece takes a piece off the board being built by MakeMove, for a
+// variant's AfterMove
+func (s *State) removePiece(square string) {
+	p := s.nextBoard.Square(square)
+	if p == piece.Empty {
+		return
+	}
 
-	index := numFens - 2
-	prevFEN := s.fens[index]
-	s.fens = s.fens[:index]
+	r := &s.history[len(s.history)-1]
+	if r.removed == nil {
+		r.removed = map[string]piece.Piece{}
+	}
+	r.removed[square] = p
 
-	// this adds prevFEN back to s.fens
-	s.LoadFEN(prevFEN)
+	s.hashSquare(square, p)
+	s.nextBoard[board.SquareToIndex(square)] = piece.Empty
 }
 
-func (s *State) PlayMoves(moves []string) {
-	for _, m := range moves {
-		s.MakeMove(move.NewMove(m[:2], m[2:]))
+// Undo takes back the last move, and gives the turn on the clock back too
+func (s *State) Undo() {
+	s.undo()
+
+	if s.Clock != nil {
+		s.Clock.Undo()
 	}
 }
 
-func (s State) GeneratePossibleMoves() []move.Move {
-	moves := []move.Move{}
+func (s *State) undo() {
+	numRecords := len(s.history)
+	assert.Assert(numRecords > 0, "cannot undo move? no moves have been made")
 
-	for _, m := range generateTmpMoves(s) {
-		s.MakeMove(m)
+	r := s.history[numRecords-1]
+	s.history = s.history[:numRecords-1]
+	s.positions = s.positions[:len(s.positions)-1]
+	s.Moves = s.Moves[:len(s.Moves)-1]
 
-		var capturedKing bool
-		for _, nextMove := range generateTmpMoves(s) {
-			if s.Piece(nextMove.Target) == piece.King*s.ActiveColor*-1 {
-				capturedKing = true
-				break
-			}
-		}
+	// first, since the moved piece may have been among them
+	for square, p := range r.removed {
+		s.nextBoard[board.SquareToIndex(square)] = p
+	}
 
-		if !capturedKing {
-			moves = append(moves, m)
+	switch {
+	case r.move.IsDrop():
+		s.nextBoard[board.SquareToIndex(r.move.Target)] = piece.Empty
+	case r.castle != nil:
+		color := r.moved.Color()
+		s.nextBoard[board.SquareToIndex(piece.CastlingSquares[color][r.castle.side])] = piece.Empty
+		s.nextBoard[board.SquareToIndex(piece.RookCastlingSquares[color][r.castle.side])] = piece.Empty
+		s.nextBoard[board.SquareToIndex(r.move.Source)] = r.moved
+		s.nextBoard[board.SquareToIndex(s.castlingRook(color, r.castle.side))] = piece.Rook * color
+	default:
+		s.nextBoard[board.SquareToIndex(r.move.Target)] = piece.Empty
+		s.nextBoard[board.SquareToIndex(r.move.Source)] = r.moved
+		if r.captured != piece.Empty {
+			s.nextBoard[board.SquareToIndex(r.capturedSquare)] = r.captured
 		}
+	}
+
+	s.Board = s.nextBoard
+	s.ActiveColor *= -1
+	s.Castling = r.castling
+	s.EnPassantTarget = r.enPassantTarget
+	s.HalfmoveClock = r.halfmoveClock
+	s.FullmoveNumber = r.fullmoveNumber
+	s.hash = r.hash
+	s.Pockets = r.pockets
+	s.promoted = r.promoted
+	s.checks = r.checks
+	s.result = r.result
+}
+
+func (s *State) PlayMoves(moves []string) {
+	for _, m := range moves {
+		mv, err := move.ParseMove(m)
+		assert.ErrIsNil(err, fmt.Sprintf("PlayMoves: %v", err))
+		s.MakeMove(mv)
+	}
+}
+
+func (s State) castlingRights() uint8 {
+	var castling uint8
+	if s.Castling[piece.White][piece.Kingside] {
+		castling |= bitboard.WhiteKingside
+	}
+	if s.Castling[piece.White][piece.Queenside] {
+		castling |= bitboard.WhiteQueenside
+	}
+	if s.Castling[piece.Black][piece.Kingside] {
+		castling |= bitboard.BlackKingside
+	}
+	if s.Castling[piece.Black][piece.Queenside] {
+		castling |= bitboard.BlackQueenside
+	}
 
-		s.Undo()
+	return castling
+}
 
+// Position is the bitboard form of the state, for fast move generation and search
+func (s State) Position() bitboard.Position {
+	opts := []func(*bitboard.Position){bitboard.WithCastlingSetup(s.setup)}
+	if s.Crazyhouse() {
+		opts = append(opts, bitboard.WithPockets(s.Pockets[piece.White], s.Pockets[piece.Black], s.promoted))
 	}
 
-	return moves
+	return bitboard.NewPosition(s.Board, s.ActiveColor, s.castlingRights(), bitboard.ParseSquare(s.EnPassantTarget), opts...)
+}
+
+func (s State) GeneratePossibleMoves() []move.Move {
+	return s.Variant().LegalMoves(&s)
 }
 
 func (s *State) IsCheck() bool {
-	s.ActiveColor *= -1
+	return s.Position().InCheck()
+}
 
-	var check bool
-	for _, m := range s.GeneratePossibleMoves() {
-		if s.Piece(m.Target) == piece.King*s.ActiveColor*-1 {
-			check = true
-			break
-		}
+// CheckGameOver also stops the clock once the game is over
+func (s *State) CheckGameOver() (GameResult, bool) {
+	res, over := s.checkGameOver()
+	if over && s.Clock != nil {
+		s.Clock.Stop()
 	}
-	s.ActiveColor *= -1
 
-	return check
+	return res, over
 }
 
-func (s *State) CheckGameOver() (gameOverState, bool) {
-	validMoves := s.GeneratePossibleMoves()
-	if len(validMoves) == 0 {
-		if s.IsCheck() {
-			switch s.ActiveColor {
-			case piece.White:
-				return blackWin, true
-			case piece.Black:
-				return whiteWin, true
-			default:
-				return no, false
-			}
-		} else {
-			return stalemate, true
-		}
-	} else if s.HalfmoveClock >= 100 {
-		return draw, true
+func (s *State) checkGameOver() (GameResult, bool) {
+	// a move made after the flag fell doesn't count, even if it mates
+	if res, ok := s.timeout(); ok {
+		return res, true
+	}
+
+	if res, over := s.Variant().Result(s, s.GeneratePossibleMoves()); over {
+		return res, true
+	} else if s.result.Over() {
+		return s.result, true
+	} else if s.Repetitions() >= 5 {
+		return Draw(FivefoldRepetition), true
+	} else if s.HalfmoveClock >= 150 {
+		return Draw(SeventyFiveMoveRule), true
+	} else if res, drawn := s.Variant().Drawn(s); drawn {
+		return res, true
 	} else {
-		return no, false
+		return GameResult{}, false
+	}
+}
+
+// timeout is a loss for the side whose flag fell, unless no series of moves
+// would let the other side mate it
+func (s State) timeout() (GameResult, bool) {
+	if s.Clock == nil {
+		return GameResult{}, false
+	}
+
+	flagged, ok := s.Clock.Flagged()
+	if !ok {
+		return GameResult{}, false
+	}
+
+	// only a side that could still mate, even with help, wins on time
+	winner := flagged * -1
+	if s.Position().CanMate(winner) {
+		return Win(winner, Timeout), true
 	}
+
+	return Draw(Timeout), true
+}
+
+// EndGame ends the game for a reason the board can't see, like a resignation,
+// a flag fall or a draw by agreement
+func (s *State) EndGame(result GameResult) {
+	s.result = result
 }
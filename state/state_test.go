@@ -1,12 +1,15 @@
 package state
 
 import (
+	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethansaxenian/chess/board"
 	"github.com/ethansaxenian/chess/move"
 	"github.com/ethansaxenian/chess/piece"
+	"github.com/ethansaxenian/chess/player"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -71,29 +74,35 @@ func TestToFen(t *testing.T) {
 func TestHandlePromotion(t *testing.T) {
 	s := NewTestStateFromFEN("8/4P3/8/8/8/8/4p3/8 w - - 0 1")
 
-	m := move.NewMove("e7", "e8")
+	m := move.NewPromotionMove("e7", "e8", piece.Queen)
 	s.handlePromotion(m, getMoveContext(*s, m))
 	assert.Equal(t, piece.Queen*piece.White, s.nextBoard.Square("e8"))
 
-	m = move.NewMove("e7", "d8")
+	m = move.NewPromotionMove("e7", "d8", piece.Knight)
 	s.handlePromotion(m, getMoveContext(*s, m))
-	assert.Equal(t, piece.Queen*piece.White, s.nextBoard.Square("d8"))
+	assert.Equal(t, piece.Knight*piece.White, s.nextBoard.Square("d8"))
 
-	m = move.NewMove("e7", "f8")
+	m = move.NewPromotionMove("e7", "f8", piece.Rook)
 	s.handlePromotion(m, getMoveContext(*s, m))
-	assert.Equal(t, piece.Queen*piece.White, s.nextBoard.Square("f8"))
+	assert.Equal(t, piece.Rook*piece.White, s.nextBoard.Square("f8"))
 
-	m = move.NewMove("e2", "e1")
+	m = move.NewPromotionMove("e2", "e1", piece.Queen)
 	s.handlePromotion(m, getMoveContext(*s, m))
 	assert.Equal(t, piece.Queen*piece.Black, s.nextBoard.Square("e1"))
 
-	m = move.NewMove("e2", "d1")
+	m = move.NewPromotionMove("e2", "d1", piece.Bishop)
 	s.handlePromotion(m, getMoveContext(*s, m))
-	assert.Equal(t, piece.Queen*piece.Black, s.nextBoard.Square("d1"))
+	assert.Equal(t, piece.Bishop*piece.Black, s.nextBoard.Square("d1"))
 
-	m = move.NewMove("e2", "f1")
+	m = move.NewPromotionMove("e2", "f1", piece.Knight)
 	s.handlePromotion(m, getMoveContext(*s, m))
-	assert.Equal(t, piece.Queen*piece.Black, s.nextBoard.Square("f1"))
+	assert.Equal(t, piece.Knight*piece.Black, s.nextBoard.Square("f1"))
+}
+
+func TestMakeMoveUnderpromotion(t *testing.T) {
+	s := NewTestStateFromFEN("8/4P3/8/8/8/8/8/k6K w - - 0 1")
+	s.PlayMoves([]string{"e7e8n"})
+	assert.Equal(t, "4N3/8/8/8/8/8/8/k6K b - - 0 1", s.FEN())
 }
 
 func TestHandleCastle(t *testing.T) {
@@ -195,7 +204,8 @@ func TestUndo(t *testing.T) {
 			s.MakeMove(move.NewMove(test.move[0], test.move[1]))
 			s.Undo()
 			assert.Equal(t, test.startingFEN, s.FEN())
-			assert.Equal(t, s.fens[len(s.fens)-1], s.FEN())
+			assert.Equal(t, test.startingFEN, s.StartingFEN())
+			assert.Empty(t, s.Moves)
 		})
 	}
 }
@@ -205,7 +215,106 @@ func TestUndoSecondTurn(t *testing.T) {
 	s.MakeMove(move.NewMove("e2", "e4"))
 	s.MakeMove(move.NewMove("e7", "e5"))
 	s.Undo()
+	assert.Equal(t, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", s.FEN())
 	s.Undo()
-	assert.Equal(t, s.fens[len(s.fens)-1], s.FEN())
 	assert.Equal(t, board.StartingFEN, s.FEN())
+	assert.Empty(t, s.Moves)
+}
+
+func TestUndoSpecialMoves(t *testing.T) {
+	tests := map[string]struct {
+		fen  string
+		move string
+	}{
+		"capture": {
+			fen:  "rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2",
+			move: "e4d5",
+		},
+		"en passant": {
+			fen:  "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+			move: "e5f6",
+		},
+		"kingside castle": {
+			fen:  "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 3 10",
+			move: "e1g1",
+		},
+		"queenside castle": {
+			fen:  "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 3 10",
+			move: "e8c8",
+		},
+		"rook capture removes castling rights": {
+			fen:  "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 3 10",
+			move: "a1a8",
+		},
+		"promotion capture": {
+			fen:  "1r2k3/P7/8/8/8/8/8/4K3 w - - 0 40",
+			move: "a7b8q",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewTestStateFromFEN(test.fen)
+			hash := s.Hash()
+
+			s.PlayMoves([]string{test.move})
+			assert.NotEqual(t, test.fen, s.FEN())
+
+			s.Undo()
+			assert.Equal(t, test.fen, s.FEN())
+			assert.Equal(t, hash, s.Hash())
+			assert.Equal(t, s.Board, s.nextBoard)
+			assert.Equal(t, 1, s.Repetitions())
+		})
+	}
+}
+
+func TestUndoKeepsCopiesIntact(t *testing.T) {
+	s := NewTestStateFromFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	s.PlayMoves([]string{"e1g1"})
+	snapshot := *s
+	fen := s.FEN()
+
+	s.PlayMoves([]string{"e8c8"})
+	s.Undo()
+	s.Undo()
+
+	assert.Equal(t, fen, snapshot.FEN())
+	assert.Equal(t, "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", s.FEN())
+}
+
+func TestActivePlayerMove(t *testing.T) {
+	s := NewStartingTestState(player.NewRandoBot(player.WithSeed(1)), testPlayer{})
+	validMoves := s.GeneratePossibleMoves()
+
+	m, err := s.ActivePlayerMove(context.Background(), validMoves)
+	assert.NoError(t, err)
+	assert.Contains(t, validMoves, m)
+
+	// the test player always answers with an empty move
+	s.MakeMove(m)
+	_, err = s.ActivePlayerMove(context.Background(), s.GeneratePossibleMoves())
+	assert.ErrorContains(t, err, "illegal move")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Players[s.ActiveColor] = player.NewRandoBot(player.WithMoveDelay(time.Minute))
+	_, err = s.ActivePlayerMove(ctx, s.GeneratePossibleMoves())
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestView(t *testing.T) {
+	s := NewTestStateFromFEN(board.StartingFEN)
+	s.PlayMoves([]string{"e2e4"})
+
+	view := s.View()
+	s.PlayMoves([]string{"e7e5"})
+
+	assert.Equal(t, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", view.FEN())
+	assert.Equal(t, board.StartingFEN, view.StartingFEN())
+	assert.Equal(t, []move.Move{move.NewMove("e2", "e4")}, view.MoveHistory())
+	assert.Equal(t, piece.Black, view.Turn())
+	assert.Len(t, view.ValidMoves(), 20)
+	assert.Equal(t, piece.Pawn*piece.White, view.Piece("e4"))
+	assert.False(t, view.IsCheck())
 }
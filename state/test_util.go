@@ -1,28 +1,25 @@
 package state
 
 import (
+	"context"
+
 	"github.com/ethansaxenian/chess/move"
-	"github.com/ethansaxenian/chess/piece"
 	"github.com/ethansaxenian/chess/player"
 )
 
 type testPlayer struct {
 }
 
-func (t testPlayer) GetMove(moves []move.Move) move.Move {
-	return move.Move{}
-}
-
-func (t testPlayer) ChoosePromotionPiece(square string) piece.Piece {
-	return piece.Queen
+func (t testPlayer) GetMove(ctx context.Context, game player.GameView) (move.Move, error) {
+	return move.Move{}, nil
 }
 
 func (t testPlayer) IsBot() bool {
 	return true
 }
 
-func NewTestStateFromFEN(fen string) *State {
-	s := StartingStateFromFEN(fen, testPlayer{}, testPlayer{})
+func NewTestStateFromFEN(fen string, opts ...func(*FENOptions)) *State {
+	s := StartingStateFromFEN(fen, testPlayer{}, testPlayer{}, opts...)
 	s.headless = true
 
 	return s
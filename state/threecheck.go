@@ -0,0 +1,116 @@
+package state
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+)
+
+const checksToWin = 3
+
+// threeCheck is won by checking the other king three times, as well as by mate
+type threeCheck struct {
+	standard
+}
+
+func (threeCheck) String() string {
+	return "3check"
+}
+
+func (threeCheck) AfterMove(s *State, _ move.Move, _ bool) {
+	color := s.ActiveColor
+	if !bitboard.NewPosition(s.nextBoard, color*-1, 0, bitboard.NoSquare).InCheck() {
+		return
+	}
+
+	// the old counts are kept for Undo
+	checks := maps.Clone(s.checks)
+	if checks == nil {
+		checks = map[piece.Piece]int{}
+	}
+	checks[color]++
+	s.checks = checks
+}
+
+func (v threeCheck) Result(s *State, moves []move.Move) (GameResult, bool) {
+	for _, color := range piece.AllColors {
+		if s.Checks(color) >= checksToWin {
+			return Win(color, ThreeChecks), true
+		}
+	}
+
+	return v.standard.Result(s, moves)
+}
+
+// anything but a lone king can give check
+func (threeCheck) Drawn(s *State) (GameResult, bool) {
+	return bareKings(s)
+}
+
+// Checks is how many times color has checked the other king, in three-check
+func (s State) Checks(color piece.Piece) int {
+	return s.checks[color]
+}
+
+// checksField is the checks each side has left to give, e.g. 3+2 once white
+// has given one
+func (s State) checksField() string {
+	return fmt.Sprintf("%d+%d", checksToWin-s.Checks(piece.White), checksToWin-s.Checks(piece.Black))
+}
+
+// checksKey tells positions apart by the checks given, for repetitions
+func (s State) checksKey() uint64 {
+	var key uint64
+	for _, color := range piece.AllColors {
+		if n := s.Checks(color); n > 0 {
+			key ^= bitboard.ZobristChecks(color, n)
+		}
+	}
+
+	return key
+}
+
+var (
+	checksLeftPattern  = regexp.MustCompile(`^(\d)\+(\d)$`)
+	checksGivenPattern = regexp.MustCompile(`^\+(\d)\+(\d)$`)
+)
+
+// splitChecks takes three-check's counters out of the FEN fields. they are
+// either the checks left after the en passant target, e.g. 3+2, or the checks
+// given at the end, e.g. +1+0. without either no checks have been given
+func splitChecks(fields []string) ([]string, map[piece.Piece]int, error) {
+	var counts []string
+	left := false
+	switch {
+	case len(fields) > 4 && checksLeftPattern.MatchString(fields[4]):
+		counts = checksLeftPattern.FindStringSubmatch(fields[4])[1:]
+		fields = slices.Delete(slices.Clone(fields), 4, 5)
+		left = true
+	case len(fields) > 4 && checksGivenPattern.MatchString(fields[len(fields)-1]):
+		counts = checksGivenPattern.FindStringSubmatch(fields[len(fields)-1])[1:]
+		fields = fields[:len(fields)-1]
+	default:
+		return fields, map[piece.Piece]int{}, nil
+	}
+
+	checks := map[piece.Piece]int{}
+	for i, color := range piece.AllColors {
+		n, _ := strconv.Atoi(counts[i])
+		if n > checksToWin {
+			return fields, checks, fmt.Errorf("%s can't have %d checks", colorNames[color], n)
+		}
+
+		if left {
+			n = checksToWin - n
+		}
+		checks[color] = n
+	}
+
+	return fields, checks, nil
+}
@@ -0,0 +1,157 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+)
+
+// Variant is a set of rules the state plays by. the hooks see the state as it
+// is when they run, and can use its unexported helpers
+type Variant interface {
+	// String is the name UCI_Variant uses for the variant
+	String() string
+	// LegalMoves generates every move the side to move can make
+	LegalMoves(s *State) []move.Move
+	// AfterMove runs once m is on the board, before the turn passes. the
+	// board being built is s.nextBoard
+	AfterMove(s *State, m move.Move, captured bool)
+	// Result decides the game when it is won or lost, given the side to
+	// move's legal moves
+	Result(s *State, moves []move.Move) (GameResult, bool)
+	// Drawn decides the game when neither side can win any more
+	Drawn(s *State) (GameResult, bool)
+}
+
+var (
+	Standard      Variant = standard{}
+	Crazyhouse    Variant = crazyhouse{}
+	ThreeCheck    Variant = threeCheck{}
+	KingOfTheHill Variant = kingOfTheHill{}
+	Atomic        Variant = atomic{}
+	Antichess     Variant = antichess{}
+)
+
+var Variants = []Variant{Standard, Crazyhouse, ThreeCheck, KingOfTheHill, Atomic, Antichess}
+
+// other names the variants go by, after normalizeVariant
+var variantAliases = map[string]Variant{
+	"standard":   Standard,
+	"threecheck": ThreeCheck,
+	"koth":       KingOfTheHill,
+}
+
+func normalizeVariant(name string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(name))
+}
+
+// ParseVariant finds a variant by its UCI name, or another common spelling
+// like "Three-check" or "King of the Hill"
+func ParseVariant(name string) (Variant, error) {
+	normalized := normalizeVariant(name)
+	for _, v := range Variants {
+		if normalizeVariant(v.String()) == normalized {
+			return v, nil
+		}
+	}
+
+	if v, ok := variantAliases[normalized]; ok {
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("unknown variant %q", name)
+}
+
+// Variant is the rules the game is played by
+func (s State) Variant() Variant {
+	if s.variant == nil {
+		return Standard
+	}
+
+	return s.variant
+}
+
+type standard struct{}
+
+func (standard) String() string {
+	return "chess"
+}
+
+func (standard) LegalMoves(s *State) []move.Move {
+	pos := s.Position()
+	legalMoves := pos.LegalMoves(make([]bitboard.Move, 0, 64))
+
+	moves := make([]move.Move, 0, len(legalMoves))
+	for _, m := range legalMoves {
+		moves = append(moves, m.ToMove())
+	}
+	move.SortMoves(moves)
+
+	return moves
+}
+
+func (standard) AfterMove(*State, move.Move, bool) {}
+
+func (standard) Result(s *State, moves []move.Move) (GameResult, bool) {
+	if len(moves) > 0 {
+		return GameResult{}, false
+	}
+
+	if s.IsCheck() {
+		return Win(s.ActiveColor*-1, Checkmate), true
+	}
+
+	return Draw(Stalemate), true
+}
+
+func (standard) Drawn(s *State) (GameResult, bool) {
+	if s.Position().InsufficientMaterial() {
+		return Draw(InsufficientMaterial), true
+	}
+
+	if s.DeadPositions && s.Position().BlockedPawnsDeadPosition() {
+		return Draw(DeadPosition), true
+	}
+
+	return GameResult{}, false
+}
+
+// bareKings is a draw in variants where a lone king can still win against
+// anything more, but not against another lone king
+func bareKings(s *State) (GameResult, bool) {
+	if s.Position().Occupied().Count() == 2 {
+		return Draw(InsufficientMaterial), true
+	}
+
+	return GameResult{}, false
+}
+
+// kingOfTheHill is won by getting the king to the center, as well as by mate
+type kingOfTheHill struct {
+	standard
+}
+
+var hill = bitboard.SquareBB(27) | bitboard.SquareBB(28) | bitboard.SquareBB(35) | bitboard.SquareBB(36)
+
+func (kingOfTheHill) String() string {
+	return "kingofthehill"
+}
+
+func (v kingOfTheHill) Result(s *State, moves []move.Move) (GameResult, bool) {
+	pos := s.Position()
+	for _, color := range piece.AllColors {
+		if pos.Pieces(color, piece.King)&hill != 0 {
+			return Win(color, KingInTheCenter), true
+		}
+	}
+
+	return v.standard.Result(s, moves)
+}
+
+// either king can always walk to the center
+func (kingOfTheHill) Drawn(*State) (GameResult, bool) {
+	return GameResult{}, false
+}
@@ -0,0 +1,271 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVariant(t *testing.T) {
+	for name, expected := range map[string]Variant{
+		"chess":            Standard,
+		"Standard":         Standard,
+		"crazyhouse":       Crazyhouse,
+		"3check":           ThreeCheck,
+		"Three-check":      ThreeCheck,
+		"King of the Hill": KingOfTheHill,
+		"atomic":           Atomic,
+		"Antichess":        Antichess,
+	} {
+		v, err := ParseVariant(name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, expected, v, name)
+	}
+
+	_, err := ParseVariant("shogi")
+	assert.Error(t, err)
+
+	for _, v := range Variants {
+		parsed, err := ParseVariant(v.String())
+		assert.NoError(t, err)
+		assert.Equal(t, v, parsed)
+	}
+}
+
+func TestVariantMoves(t *testing.T) {
+	tests := map[string]struct {
+		variant  Variant
+		fen      string
+		move     string
+		expected string
+	}{
+		"three-check counts a check": {
+			variant:  ThreeCheck,
+			fen:      "4k3/8/8/8/8/8/8/R3K3 w - - 3+3 0 1",
+			move:     "a1a8",
+			expected: "R3k3/8/8/8/8/8/8/4K3 b - - 2+3 1 1",
+		},
+		"atomic capture explodes": {
+			variant:  Atomic,
+			fen:      "4k3/8/8/2npb3/5N2/8/8/4K3 w - - 0 1",
+			move:     "f4d5",
+			expected: "4k3/8/8/8/8/8/8/4K3 b - - 0 1",
+		},
+		"atomic pawns survive explosions": {
+			variant:  Atomic,
+			fen:      "4k3/8/3pp3/2nq4/3P4/8/8/4K3 w - - 0 1",
+			move:     "d4c5",
+			expected: "4k3/8/3pp3/8/8/8/8/4K3 b - - 0 1",
+		},
+		"atomic en passant explodes on the target": {
+			variant:  Atomic,
+			fen:      "4k3/3n4/8/3Pp3/8/8/8/4K3 w - e6 0 1",
+			move:     "d5e6",
+			expected: "4k3/8/8/8/8/8/8/4K3 b - - 0 1",
+		},
+		"atomic explosion takes castling rights": {
+			variant:  Atomic,
+			fen:      "r3k2r/8/8/8/8/8/1b6/R3K2R b KQkq - 0 1",
+			move:     "b2a1",
+			expected: "r3k2r/8/8/8/8/8/8/4K2R w Kkq - 0 2",
+		},
+		"antichess king promotion": {
+			variant:  Antichess,
+			fen:      "8/P7/8/8/8/8/8/7k w - - 0 1",
+			move:     "a7a8k",
+			expected: "K7/8/8/8/8/8/8/7k b - - 0 1",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewTestStateFromFEN(test.fen, WithVariant(test.variant))
+			m, err := move.ParseMove(test.move)
+			assert.NoError(t, err)
+			assert.Contains(t, s.GeneratePossibleMoves(), m)
+
+			hash := s.Hash()
+			s.MakeMove(m)
+			assert.Equal(t, test.expected, s.FEN())
+			assert.Equal(t, s.Position().Hash(), s.Hash())
+
+			s.Undo()
+			assert.Equal(t, test.fen, s.FEN())
+			assert.Equal(t, hash, s.Hash())
+		})
+	}
+}
+
+func TestVariantLegalMoves(t *testing.T) {
+	tests := map[string]struct {
+		variant Variant
+		fen     string
+		legal   []string
+		illegal []string
+	}{
+		"atomic kings can't capture": {
+			variant: Atomic,
+			fen:     "4k3/8/8/8/8/8/4n3/4K3 w - - 0 1",
+			illegal: []string{"e1e2"},
+		},
+		"atomic captures can't blow up the mover's king": {
+			variant: Atomic,
+			fen:     "4k3/8/8/8/8/8/R2n4/4K3 w - - 0 1",
+			legal:   []string{"a2a7"},
+			illegal: []string{"a2d2"},
+		},
+		"atomic kings next to each other aren't in check": {
+			variant: Atomic,
+			fen:     "8/8/8/8/8/3k4/3K4/7r w - - 0 1",
+			legal:   []string{"d2c3", "d2e3"},
+			illegal: []string{"d2c1"},
+		},
+		"atomic blowing up the other king ignores check": {
+			variant: Atomic,
+			fen:     "3rk3/4p3/8/8/8/8/8/3QK3 w - - 0 1",
+			legal:   []string{"d1d8"},
+		},
+		"antichess captures are compulsory": {
+			variant: Antichess,
+			fen:     "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1",
+			legal:   []string{"e4d5"},
+			illegal: []string{"e4e5", "e1d1"},
+		},
+		"antichess kings can be captured and left in check": {
+			variant: Antichess,
+			fen:     "8/8/8/8/8/8/3q4/4K3 w - - 0 1",
+			legal:   []string{"e1d2"},
+			illegal: []string{"e1f1"},
+		},
+		"antichess has no castling": {
+			variant: Antichess,
+			fen:     "4k3/8/8/8/8/8/8/4K2R w K - 0 1",
+			illegal: []string{"e1g1"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			moves := NewTestStateFromFEN(test.fen, WithVariant(test.variant)).GeneratePossibleMoves()
+			for _, lan := range test.legal {
+				m, err := move.ParseMove(lan)
+				assert.NoError(t, err)
+				assert.Contains(t, moves, m, lan)
+			}
+			for _, lan := range test.illegal {
+				m, err := move.ParseMove(lan)
+				assert.NoError(t, err)
+				assert.NotContains(t, moves, m, lan)
+			}
+		})
+	}
+}
+
+func TestVariantGameOver(t *testing.T) {
+	tests := map[string]struct {
+		variant  Variant
+		fen      string
+		expected GameResult
+	}{
+		"three checks": {
+			variant:  ThreeCheck,
+			fen:      "4k3/8/8/8/8/8/8/R3K3 w - - 0+2 0 1",
+			expected: Win(piece.White, ThreeChecks),
+		},
+		"three-check bare kings": {
+			variant:  ThreeCheck,
+			fen:      "4k3/8/8/8/8/8/8/4K3 w - - 3+3 0 1",
+			expected: Draw(InsufficientMaterial),
+		},
+		"king in the center": {
+			variant:  KingOfTheHill,
+			fen:      "8/8/8/4k3/8/8/8/4K3 w - - 0 1",
+			expected: Win(piece.Black, KingInTheCenter),
+		},
+		"king of the hill checkmate": {
+			variant:  KingOfTheHill,
+			fen:      "R3k3/8/4K3/8/8/8/8/8 b - - 0 1",
+			expected: Win(piece.White, Checkmate),
+		},
+		"exploded king": {
+			variant:  Atomic,
+			fen:      "rnb4r/ppp1p1pp/3q4/3p4/8/8/PPPPPPPP/RNBQKB1R b KQ - 0 3",
+			expected: Win(piece.White, Explosion),
+		},
+		"atomic bare kings": {
+			variant:  Atomic,
+			fen:      "8/8/8/8/8/3k4/8/3K4 w - - 0 1",
+			expected: Draw(InsufficientMaterial),
+		},
+		"antichess losing all pieces": {
+			variant:  Antichess,
+			fen:      "8/8/8/8/8/8/8/7q w - - 0 1",
+			expected: Win(piece.White, AllPiecesLost),
+		},
+		"antichess stalemate": {
+			variant:  Antichess,
+			fen:      "8/8/8/8/8/p7/P7/7q w - - 0 1",
+			expected: Win(piece.White, Stalemate),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, over := NewTestStateFromFEN(test.fen, WithVariant(test.variant)).CheckGameOver()
+			assert.True(t, over)
+			assert.Equal(t, test.expected, res)
+		})
+	}
+
+	// a lone king can still walk to the center
+	_, over := NewTestStateFromFEN("8/8/8/8/8/8/8/k3K3 w - - 0 1", WithVariant(KingOfTheHill)).CheckGameOver()
+	assert.False(t, over)
+}
+
+func TestThreeCheckFEN(t *testing.T) {
+	s := NewTestStateFromFEN(board.StartingFEN, WithVariant(ThreeCheck))
+	assert.Equal(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 3+3 0 1", s.FEN())
+
+	// the checks given at the end are read too
+	s = NewTestStateFromFEN("4k3/8/8/8/8/8/8/R3K3 w - - 0 1 +1+2", WithVariant(ThreeCheck))
+	assert.Equal(t, 1, s.Checks(piece.White))
+	assert.Equal(t, 2, s.Checks(piece.Black))
+	assert.Equal(t, "4k3/8/8/8/8/8/8/R3K3 w - - 2+1 0 1", s.FEN())
+
+	_, err := ParseFEN("4k3/8/8/8/8/8/8/R3K3 w - - 4+3 0 1", WithVariant(ThreeCheck))
+	var fenErr *FENError
+	if assert.ErrorAs(t, err, &fenErr) {
+		assert.Equal(t, "checks", fenErr.Field)
+	}
+
+	// the counters are only read in three-check
+	_, err = ParseFEN("4k3/8/8/8/8/8/8/R3K3 w - - 3+3 0 1")
+	assert.Error(t, err)
+}
+
+func TestThreeCheckRepetitions(t *testing.T) {
+	s := NewTestStateFromFEN("4k3/8/8/8/8/8/8/R3K3 w - - 0 1", WithVariant(ThreeCheck))
+
+	// the rook checks every time it reaches a8, so the position never repeats
+	s.PlayMoves([]string{"a1a8", "e8e7", "a8a1", "e7e8", "a1a8", "e8e7", "a8a1", "e7e8"})
+	assert.Equal(t, 1, s.Repetitions())
+
+	s = NewTestStateFromFEN("4k3/8/8/8/8/8/8/R3K3 w - - 0 1", WithVariant(ThreeCheck))
+	s.PlayMoves([]string{"a1b1", "e8e7", "b1a1", "e7e8", "a1b1", "e8e7", "b1a1", "e7e8"})
+	assert.Equal(t, 3, s.Repetitions())
+}
+
+func TestAntichessDropsCastlingRights(t *testing.T) {
+	s := NewTestStateFromFEN(board.StartingFEN, WithVariant(Antichess))
+	assert.Equal(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 1", s.FEN())
+}
+
+func TestAtomicFENLegality(t *testing.T) {
+	// touching kings can't be in check, and antichess doesn't need kings
+	assert.NoError(t, ValidateFEN("8/8/8/8/8/3k4/3K4/3r4 b - - 0 1", WithVariant(Atomic)))
+	assert.NoError(t, ValidateFEN("8/8/8/8/8/8/8/q6q w - - 0 1", WithVariant(Antichess)))
+	assert.Error(t, ValidateFEN("8/8/8/8/8/8/8/q6q w - - 0 1"))
+}
@@ -0,0 +1,71 @@
+package state
+
+import (
+	"slices"
+	"time"
+
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/ethansaxenian/chess/player"
+)
+
+// gameView is a snapshot of the state, so a player can read it from another
+// goroutine while the game goes on
+type gameView struct {
+	s          State
+	validMoves []move.Move
+}
+
+// View is the read-only view of the state given to players
+func (s State) View() player.GameView {
+	return gameView{s, s.GeneratePossibleMoves()}
+}
+
+func (v gameView) FEN() string {
+	return v.s.FEN()
+}
+
+func (v gameView) StartingFEN() string {
+	return v.s.StartingFEN()
+}
+
+func (v gameView) MoveHistory() []move.Move {
+	return slices.Clone(v.s.Moves)
+}
+
+func (v gameView) Turn() piece.Piece {
+	return v.s.ActiveColor
+}
+
+func (v gameView) ValidMoves() []move.Move {
+	return slices.Clone(v.validMoves)
+}
+
+func (v gameView) Piece(square string) piece.Piece {
+	return v.s.Piece(square)
+}
+
+func (v gameView) IsCheck() bool {
+	return v.s.IsCheck()
+}
+
+func (v gameView) Chess960() bool {
+	return v.s.Chess960()
+}
+
+func (v gameView) Variant() string {
+	return v.s.Variant().String()
+}
+
+func (v gameView) TimeLeft(color piece.Piece) (time.Duration, bool) {
+	if v.s.Clock == nil {
+		return 0, false
+	}
+
+	return v.s.Clock.Remaining(color), true
+}
+
+func (v gameView) Position() bitboard.Position {
+	return v.s.Position()
+}
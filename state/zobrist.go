@@ -0,0 +1,41 @@
+package state
+
+import (
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/piece"
+)
+
+// Hash is the position's zobrist key, kept up to date by MakeMove and Undo.
+// it uses the polyglot layout, see bitboard.ZobristPiece
+func (s State) Hash() uint64 {
+	return s.hash
+}
+
+func (s *State) hashSquare(square string, p piece.Piece) {
+	if p != piece.Empty {
+		s.hash ^= bitboard.ZobristPiece(p, board.SquareToIndex(square))
+	}
+}
+
+// stateKey hashes everything but the pieces: castling rights, the en passant
+// file and the side to move
+func (s State) stateKey() uint64 {
+	pos := s.Position()
+	key := bitboard.ZobristCastling(s.castlingRights()) ^ pos.EnPassantKey()
+
+	if s.ActiveColor == piece.White {
+		key ^= bitboard.ZobristWhiteToMove()
+	}
+
+	return key
+}
+
+// fenContext defers building the FEN until an assertion actually fails
+type fenContext struct {
+	s *State
+}
+
+func (f fenContext) String() string {
+	return f.s.FEN()
+}
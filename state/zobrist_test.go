@@ -0,0 +1,62 @@
+package state
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashIsIncremental(t *testing.T) {
+	fens := []string{
+		board.StartingFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	}
+
+	rng := rand.New(rand.NewSource(1))
+
+	for _, fen := range fens {
+		t.Run(fen, func(t *testing.T) {
+			for game := 0; game < 5; game++ {
+				s := NewTestStateFromFEN(fen)
+				var hashes []uint64
+				var fens []string
+
+				for ply := 0; ply < 60; ply++ {
+					moves := s.GeneratePossibleMoves()
+					if len(moves) == 0 {
+						break
+					}
+
+					hashes = append(hashes, s.Hash())
+					fens = append(fens, s.FEN())
+					s.MakeMove(moves[rng.Intn(len(moves))])
+					if !assert.Equal(t, s.Position().Hash(), s.Hash(), s.FEN()) {
+						return
+					}
+				}
+
+				for i := len(hashes) - 1; i >= 0; i-- {
+					s.Undo()
+					assert.Equal(t, hashes[i], s.Hash())
+					assert.Equal(t, fens[i], s.FEN())
+				}
+			}
+		})
+	}
+}
+
+func TestHashTranspositions(t *testing.T) {
+	a := NewTestStateFromFEN(board.StartingFEN)
+	a.PlayMoves([]string{"e2e4", "e7e5", "g1f3"})
+
+	b := NewTestStateFromFEN(board.StartingFEN)
+	b.PlayMoves([]string{"g1f3", "e7e5", "e2e4"})
+
+	assert.Equal(t, a.Hash(), b.Hash())
+	assert.NotEqual(t, a.FEN(), b.FEN())
+	assert.NotEqual(t, NewTestStateFromFEN(board.StartingFEN).Hash(), a.Hash())
+}
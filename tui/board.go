@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+)
+
+// the board is drawn under the players' names, one line per rank: a rank
+// label, then three columns per square
+const (
+	boardTop    = 1
+	boardLeft   = 2
+	squareWidth = 3
+)
+
+const (
+	lightSquare    = "\033[48;2;194;167;120m"
+	darkSquare     = "\033[48;2;131;99;69m"
+	lastMoveSquare = "\033[48;2;170;162;58m"
+	selectedSquare = "\033[48;2;100;111;64m"
+	targetSquare   = "\033[48;2;84;128;148m"
+	checkSquare    = "\033[48;2;200;60;50m"
+
+	whitePiece = "\033[38;2;255;255;255m"
+	blackPiece = "\033[38;2;0;0;0m"
+	reset      = "\033[0m"
+)
+
+// flipped draws the board from black's side, when only black is human
+func (m model) flipped() bool {
+	return m.Players[piece.White].IsBot() && !m.Players[piece.Black].IsBot()
+}
+
+// squareAt is the square drawn at a cell of the view, for mouse clicks
+func (m model) squareAt(x, y int) (int, bool) {
+	row, col := y-boardTop, (x-boardLeft)/squareWidth
+	if x < boardLeft || row < 0 || row > 7 || col > 7 {
+		return bitboard.NoSquare, false
+	}
+
+	if m.flipped() {
+		return row*8 + 7 - col, true
+	}
+
+	return (7-row)*8 + col, true
+}
+
+// movesFrom is the legal moves of the piece on sq, none if it isn't the
+// human's turn
+func (m model) movesFrom(sq int) []move.Move {
+	if sq == bitboard.NoSquare || m.ActivePlayer().IsBot() {
+		return nil
+	}
+
+	var moves []move.Move
+	for _, mv := range m.GeneratePossibleMoves() {
+		if !mv.IsDrop() && mv.Source == bitboard.SquareName(sq) {
+			moves = append(moves, mv)
+		}
+	}
+
+	return moves
+}
+
+// squareColor picks the background for sq, the most important highlight first
+func (m model) squareColor(sq int, targets map[int]bool) string {
+	square := bitboard.SquareName(sq)
+
+	switch {
+	case m.IsCheck() && m.Piece(square) == piece.King*m.ActiveColor:
+		return checkSquare
+	case sq == m.selected:
+		return selectedSquare
+	case targets[sq]:
+		return targetSquare
+	}
+
+	if n := len(m.Moves); n > 0 {
+		last := m.Moves[n-1]
+		if last.Target == square || (!last.IsDrop() && last.Source == square) {
+			return lastMoveSquare
+		}
+	}
+
+	if (sq/8+sq%8)%2 == 0 {
+		return darkSquare
+	}
+
+	return lightSquare
+}
+
+func (m model) boardView() string {
+	targets := map[int]bool{}
+	for _, mv := range m.movesFrom(m.selected) {
+		targets[bitboard.ParseSquare(mv.Target)] = true
+	}
+
+	var sb strings.Builder
+	for row := 0; row < 8; row++ {
+		rank := 7 - row
+		if m.flipped() {
+			rank = row
+		}
+		fmt.Fprintf(&sb, "%d ", rank+1)
+
+		for col := 0; col < 8; col++ {
+			file := col
+			if m.flipped() {
+				file = 7 - col
+			}
+			sq := rank*8 + file
+			p := m.Board[sq]
+
+			pieceColor := whitePiece
+			if p.Color() == piece.Black {
+				pieceColor = blackPiece
+			}
+
+			symbol := p.String()
+			if p == piece.Empty && targets[sq] {
+				symbol = "•"
+			}
+
+			left, right := " ", " "
+			if sq == m.cursor && !m.typing {
+				left, right = "[", "]"
+			}
+
+			sb.WriteString(m.squareColor(sq, targets) + pieceColor + left + symbol + right + reset)
+		}
+
+		sb.WriteString("\n")
+	}
+
+	files := "abcdefgh"
+	if m.flipped() {
+		files = "hgfedcba"
+	}
+	sb.WriteString(" ")
+	for _, f := range files {
+		fmt.Fprintf(&sb, "  %c", f)
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
+}
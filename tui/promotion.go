@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+)
+
+// the promotion picker is drawn under the file labels, a square per piece
+const pickerTop = boardTop + 9
+
+// the picker's order, best first. kings are only there in antichess
+var promotionOrder = []piece.Piece{piece.Queen, piece.Rook, piece.Bishop, piece.Knight, piece.King}
+
+func (m model) promoting() bool {
+	return len(m.promotions) > 0
+}
+
+// startPromotion opens the picker on moves, which differ only by promotion
+func (m model) startPromotion(moves []move.Move) model {
+	m.promotions = slices.Clone(moves)
+	slices.SortFunc(m.promotions, func(a, b move.Move) int {
+		return slices.Index(promotionOrder, a.Promotion) - slices.Index(promotionOrder, b.Promotion)
+	})
+	m.promotion = 0
+
+	return m
+}
+
+func (m model) pick(i int) (tea.Model, tea.Cmd) {
+	mv := m.promotions[i]
+	m.promotions = nil
+	return m, func() tea.Msg { return mv }
+}
+
+func (m model) onPromotionKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key := msg.String(); key {
+	case "left", "h":
+		m.promotion = max(m.promotion-1, 0)
+	case "right", "l":
+		m.promotion = min(m.promotion+1, len(m.promotions)-1)
+	case "enter", " ":
+		return m.pick(m.promotion)
+	case "esc":
+		m.promotions = nil
+	default:
+		// or straight to a piece by its letter
+		for i, mv := range m.promotions {
+			if key == strings.ToLower(mv.Promotion.FEN()) {
+				return m.pick(i)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// onPromotionClick picks the piece clicked on, and a click anywhere else
+// cancels the promotion
+func (m model) onPromotionClick(x, y int) (tea.Model, tea.Cmd) {
+	i := (x - boardLeft) / squareWidth
+	if y == pickerTop && x >= boardLeft && i < len(m.promotions) {
+		return m.pick(i)
+	}
+
+	m.promotions = nil
+	return m, nil
+}
+
+func (m model) promotionView() string {
+	var sb strings.Builder
+	sb.WriteString("  ")
+
+	letters := make([]string, 0, len(m.promotions))
+	for i, mv := range m.promotions {
+		background := lightSquare
+		if i == m.promotion {
+			background = selectedSquare
+		}
+
+		pieceColor := whitePiece
+		if m.ActiveColor == piece.Black {
+			pieceColor = blackPiece
+		}
+
+		sb.WriteString(background + pieceColor + " " + (mv.Promotion * m.ActiveColor).String() + " " + reset)
+		letters = append(letters, strings.ToLower(mv.Promotion.FEN()))
+	}
+
+	sb.WriteString("  promote to (" + strings.Join(letters, "/") + ", esc cancels)\n")
+
+	return sb.String()
+}
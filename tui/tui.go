@@ -1,54 +1,188 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"slices"
-
 	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/clock"
 	"github.com/ethansaxenian/chess/move"
 	"github.com/ethansaxenian/chess/piece"
-	"github.com/ethansaxenian/chess/player"
 	"github.com/ethansaxenian/chess/state"
 )
 
 type botTurnMsg struct{}
 
+type botErrMsg struct {
+	err error
+}
+
+// tickMsg redraws the clocks while a side is thinking
+type tickMsg struct{}
+
+// how often the clocks are redrawn
+const tickInterval = 100 * time.Millisecond
+
 func botTurn() tea.Msg {
 	return botTurnMsg{}
 }
 
+func tick() tea.Cmd {
+	return tea.Tick(tickInterval, func(time.Time) tea.Msg {
+		return tickMsg{}
+	})
+}
+
 type model struct {
 	*state.State
 	input textinput.Model
+
+	// cursor is the square the keyboard is on, and selected the piece picked
+	// up to move, or bitboard.NoSquare
+	cursor   int
+	selected int
+	// typing sends keys to the move input instead of the board
+	typing bool
+	// inputErr says why the typed move wasn't played
+	inputErr error
+
+	// promotions are the moves a pawn reaching the last rank can make, while
+	// the picker is open, and promotion the one the picker is on
+	promotions []move.Move
+	promotion  int
+
+	// ctx is cancelled on quit, to stop a bot that is still thinking
+	ctx    context.Context
+	cancel context.CancelFunc
+	err    error
 }
 
-func initialModel(white, black player.Player) model {
+func initialModel(s *state.State) model {
 	ti := textinput.New()
-	ti.Focus()
-	ti.CharLimit = 4
-	ti.Width = 4
+	ti.CharLimit = maxMoveLength
+	ti.Width = maxMoveLength
+	ti.ShowSuggestions = true
+	// up and down step through the suggestions, so tab can leave the input
+	ti.KeyMap.AcceptSuggestion = key.NewBinding(key.WithKeys("right"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m := model{
+		State:    s,
+		input:    ti,
+		selected: bitboard.NoSquare,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	// start on the king of the side the human plays
+	m.cursor = bitboard.ParseSquare("e1")
+	if m.flipped() {
+		m.cursor = bitboard.ParseSquare("e8")
+	}
+	m.suggestMoves()
+
+	return m
+}
+
+// the longest moves typed are SAN like Qa1xb2=Q+, or a drop like N@e4
+const maxMoveLength = 10
+
+// suggestMoves completes typed moves from the legal ones, in SAN
+func (m *model) suggestMoves() {
+	if m.ActivePlayer().IsBot() {
+		m.input.SetSuggestions(nil)
+		return
+	}
+
+	var sans []string
+	for _, mv := range m.GeneratePossibleMoves() {
+		sans = append(sans, m.SAN(mv))
+	}
+	m.input.SetSuggestions(sans)
+}
+
+// completions is the suggestions matching what has been typed so far
+func (m model) completions() []string {
+	typed := strings.ToLower(m.input.Value())
+	if typed == "" {
+		return nil
+	}
+
+	var matches []string
+	for _, san := range m.input.AvailableSuggestions() {
+		if strings.HasPrefix(strings.ToLower(san), typed) {
+			matches = append(matches, san)
+		}
+	}
+
+	return matches
+}
+
+// suggestionFor is the one suggestion val spells in another case, like Nf3
+// for "nf3". a lowercase b is read as the file first, by ParseSAN
+func (m model) suggestionFor(val string) (string, bool) {
+	var found []string
+	for _, san := range m.input.AvailableSuggestions() {
+		if strings.EqualFold(strings.TrimRight(san, "+#"), strings.TrimRight(val, "+#")) {
+			found = append(found, san)
+		}
+	}
 
-	return model{
-		State: state.StartingState(white, black),
-		input: ti,
+	if len(found) != 1 {
+		return "", false
 	}
+
+	return found[0], true
 }
 
 func (m model) Init() tea.Cmd {
+	if _, over := m.CheckGameOver(); over {
+		return tea.Quit
+	}
+
+	cmds := []tea.Cmd{textinput.Blink}
+
 	if m.ActivePlayer().IsBot() {
-		return tea.Batch(botTurn, textinput.Blink)
+		cmds = append(cmds, botTurn)
+	}
+
+	if m.Clock != nil {
+		cmds = append(cmds, tick())
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func (m model) flagged() bool {
+	if m.Clock == nil {
+		return false
 	}
 
-	return textinput.Blink
+	_, flagged := m.Clock.Flagged()
+	return flagged
 }
 
 func (m model) View() string {
 	view := fmt.Sprintf("%s vs %s\n", m.PlayerRepr(piece.White), m.PlayerRepr(piece.Black))
 
-	view += m.Board.String() + m.FEN() + "\n"
+	view += m.boardView()
+	if m.promoting() {
+		view += m.promotionView()
+	}
+	view += m.FEN() + "\n"
+
+	if m.Clock != nil {
+		view += fmt.Sprintf("white %s  black %s\n", clock.Format(m.Clock.Remaining(piece.White)), clock.Format(m.Clock.Remaining(piece.Black)))
+	}
 
 	view += fmt.Sprintf("%s to play\n\n", m.ActivePlayerRepr())
 
@@ -58,6 +192,19 @@ func (m model) View() string {
 
 	if !m.ActivePlayer().IsBot() {
 		view += m.input.View()
+		switch {
+		case m.inputErr != nil:
+			view += "  " + m.inputErr.Error()
+		case m.typing:
+			view += "  " + strings.Join(m.completions(), " ")
+		}
+		view += "\n"
+	}
+
+	if m.typing {
+		view += "enter plays the move (e.g. Nf3, O-O or e2e4), right completes it, esc or tab goes back to the board\n"
+	} else {
+		view += "arrows/hjkl or the mouse pick a square, enter/space selects, tab types a move\n"
 	}
 
 	return view
@@ -69,32 +216,161 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case botTurnMsg:
 		return m.getBotMove()
 
+	case botErrMsg:
+		m.err = msg.err
+		return m, tea.Quit
+
+	case tickMsg:
+		if m.flagged() {
+			return m, tea.Quit
+		}
+		return m, tick()
+
 	case move.Move:
 		return m.onMove(msg)
 
 	case tea.KeyMsg:
 		return m.onKey(msg)
+
+	case tea.MouseMsg:
+		return m.onMouse(msg)
 	}
 
 	return m, nil
 }
 
 func (m model) onKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.Type {
-
-	case tea.KeyCtrlC:
+	switch {
+	case msg.Type == tea.KeyCtrlC:
+		m.cancel()
 		return m, tea.Quit
 
+	case m.promoting():
+		return m.onPromotionKey(msg)
+
+	case msg.Type == tea.KeyTab:
+		m.typing = !m.typing
+		if m.typing {
+			return m, m.input.Focus()
+		}
+		m.input.Blur()
+		return m, nil
+	}
+
+	if m.typing {
+		return m.onTypingKey(msg)
+	}
+
+	return m.onBoardKey(msg)
+}
+
+func (m model) onTypingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
 	case tea.KeyEnter:
 		return m.onEnter()
 
+	case tea.KeyEsc:
+		m.typing = false
+		m.input.Blur()
+		return m, nil
+
 	default:
+		m.inputErr = nil
 		var cmd tea.Cmd
 		m.input, cmd = m.input.Update(msg)
 		return m, cmd
+	}
+}
+
+func (m model) onBoardKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// directions are as drawn, so they turn around with the board
+	up, right := 1, 1
+	if m.flipped() {
+		up, right = -1, -1
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		m.moveCursor(up, 0)
+	case "down", "j":
+		m.moveCursor(-up, 0)
+	case "left", "h":
+		m.moveCursor(0, -right)
+	case "right", "l":
+		m.moveCursor(0, right)
+	case "enter", " ":
+		return m.choose(m.cursor)
+	case "esc":
+		m.selected = bitboard.NoSquare
+	}
+
+	return m, nil
+}
+
+// moveCursor steps the cursor by ranks and files, stopping at the edge
+func (m *model) moveCursor(ranks, files int) {
+	rank, file := m.cursor/8+ranks, m.cursor%8+files
+	if rank >= 0 && rank < 8 && file >= 0 && file < 8 {
+		m.cursor = rank*8 + file
+	}
+}
+
+func (m model) onMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft {
+		return m, nil
+	}
+
+	if m.promoting() {
+		return m.onPromotionClick(msg.X, msg.Y)
+	}
+
+	sq, ok := m.squareAt(msg.X, msg.Y)
+	if !ok {
+		return m, nil
+	}
+
+	m.cursor = sq
+	return m.choose(sq)
+}
+
+// choose moves the selected piece to sq if it can go there, and otherwise
+// picks up the piece on sq, or puts down the selected one when sq is chosen
+// again
+func (m model) choose(sq int) (tea.Model, tea.Cmd) {
+	if moves := m.movesTo(sq); len(moves) > 0 {
+		m.selected = bitboard.NoSquare
+		return m.play(moves)
+	}
+
+	if sq != m.selected && len(m.movesFrom(sq)) > 0 {
+		m.selected = sq
+	} else {
+		m.selected = bitboard.NoSquare
+	}
+
+	return m, nil
+}
 
+// movesTo is the selected piece's moves to sq, more than one for a promotion
+func (m model) movesTo(sq int) []move.Move {
+	var found []move.Move
+	for _, mv := range m.movesFrom(m.selected) {
+		if mv.Target == bitboard.SquareName(sq) {
+			found = append(found, mv)
+		}
 	}
 
+	return found
+}
+
+// play makes a move, or asks which piece to promote to when there's a choice
+func (m model) play(moves []move.Move) (tea.Model, tea.Cmd) {
+	if len(moves) > 1 {
+		return m.startPromotion(moves), nil
+	}
+
+	mv := moves[0]
+	return m, func() tea.Msg { return mv }
 }
 
 func (m model) getBotMove() (tea.Model, tea.Cmd) {
@@ -102,37 +378,79 @@ func (m model) getBotMove() (tea.Model, tea.Cmd) {
 	if len(validMoves) == 0 {
 		return m, tea.Quit
 	}
-	mv := m.ActivePlayer().GetMove(validMoves)
-	return m, func() tea.Msg { return mv }
+
+	// the bot thinks in the background so the ui can still quit
+	s := *m.State
+	return m, func() tea.Msg {
+		ctx, cancel := s.TurnContext(m.ctx)
+		defer cancel()
+
+		mv, err := s.ActivePlayerMove(ctx, validMoves)
+		// running out of time is picked up by the next tick
+		if errors.Is(err, context.DeadlineExceeded) && m.ctx.Err() == nil {
+			return nil
+		}
+		if err != nil {
+			return botErrMsg{err}
+		}
+		return mv
+	}
 }
 
 func (m model) onEnter() (tea.Model, tea.Cmd) {
-	val := m.input.Value()
+	val := strings.TrimSpace(m.input.Value())
+	if val == "" {
+		return m, nil
+	}
 
-	if len(val) == 4 {
-		mv := move.NewMove(val[:2], val[2:])
+	// long algebraic like e2e4 first, then SAN
+	if mv, err := move.ParseMove(val); err == nil && slices.Contains(m.GeneratePossibleMoves(), mv) {
+		return m, func() tea.Msg { return mv }
+	}
 
-		validMoves := m.GeneratePossibleMoves()
-		if slices.Contains(validMoves, mv) {
-			return m, func() tea.Msg { return mv }
+	mv, err := m.ParseSAN(val)
+	if err != nil && !errors.Is(err, state.ErrAmbiguousSAN) {
+		if san, ok := m.suggestionFor(val); ok {
+			mv, err = m.ParseSAN(san)
+		}
+	}
+	if errors.Is(err, state.ErrAmbiguousSAN) {
+		// a pawn reaching the last rank without saying what it becomes
+		if queen, qerr := m.ParseSAN(val + "=Q"); qerr == nil {
+			m.input.Reset()
+			return m.startPromotion(m.promotionsOf(queen)), nil
 		}
 	}
+	if err != nil {
+		m.inputErr = err
+		return m, nil
+	}
 
-	m.input.Reset()
-	return m, nil
+	return m, func() tea.Msg { return mv }
 }
 
-func (m *model) onMove(mv move.Move) (tea.Model, tea.Cmd) {
-	m.MakeMove(mv)
-	m.input.Reset()
-
-	if m.HalfmoveClock == 100 {
-		return m, tea.Quit
+// promotionsOf is every promotion with the same pawn move as mv
+func (m model) promotionsOf(mv move.Move) []move.Move {
+	var moves []move.Move
+	for _, other := range m.GeneratePossibleMoves() {
+		if other.Source == mv.Source && other.Target == mv.Target && !other.IsDrop() {
+			moves = append(moves, other)
+		}
 	}
 
-	validMoves := m.GeneratePossibleMoves()
+	return moves
+}
 
-	if len(validMoves) == 0 && m.IsCheck() {
+func (m model) onMove(mv move.Move) (tea.Model, tea.Cmd) {
+	m.MakeMove(mv)
+	m.input.Reset()
+	m.inputErr = nil
+	m.selected = bitboard.NoSquare
+	m.promotions = nil
+	m.suggestMoves()
+
+	// the variant's own wins end the game too, and RunTUI shows the result
+	if _, over := m.CheckGameOver(); over || m.HalfmoveClock == 100 {
 		return m, tea.Quit
 	}
 
@@ -142,15 +460,29 @@ func (m *model) onMove(mv move.Move) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func RunTUI(white, black player.Player) {
-	m := initialModel(white, black)
-	p := tea.NewProgram(m)
-	if _, err := p.Run(); err != nil {
+// RunTUI plays out a game that is ready to start, with its clock running if it has one
+func RunTUI(s *state.State) {
+	m := initialModel(s)
+	defer m.cancel()
+
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	final, err := p.Run()
+	if err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
 	}
 
-	if res, over := m.CheckGameOver(); over {
+	// the alt screen is gone once the program exits, so leave the final board
+	last := final.(model)
+	last.cursor = bitboard.NoSquare
+	fmt.Print(last.boardView())
+
+	if err := last.err; err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if res, over := last.CheckGameOver(); over {
 		fmt.Println(res)
 	}
 }
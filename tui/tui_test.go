@@ -0,0 +1,344 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ethansaxenian/chess/bitboard"
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/ethansaxenian/chess/player"
+	"github.com/ethansaxenian/chess/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestModel(fen string, white, black player.Player) model {
+	return initialModel(state.StartingStateFromFEN(fen, white, black))
+}
+
+func TestSquareAt(t *testing.T) {
+	human, bot := player.NewHumanPlayer("human"), player.NewRandoBot()
+
+	tests := map[string]struct {
+		white, black player.Player
+		x, y         int
+		expected     string
+	}{
+		"top left":             {white: human, black: bot, x: 2, y: 1, expected: "a8"},
+		"middle of a square":   {white: human, black: bot, x: 15, y: 4, expected: "e5"},
+		"bottom right":         {white: human, black: bot, x: 25, y: 8, expected: "h1"},
+		"flipped top left":     {white: bot, black: human, x: 2, y: 1, expected: "h1"},
+		"flipped bottom right": {white: bot, black: human, x: 25, y: 8, expected: "a8"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newTestModel("8/8/8/8/8/8/8/K6k w - - 0 1", test.white, test.black)
+			sq, ok := m.squareAt(test.x, test.y)
+			assert.True(t, ok)
+			assert.Equal(t, test.expected, bitboard.SquareName(sq))
+		})
+	}
+
+	m := newTestModel("8/8/8/8/8/8/8/K6k w - - 0 1", human, bot)
+	for _, cell := range [][2]int{{0, 1}, {2, 0}, {26, 1}, {2, 9}} {
+		_, ok := m.squareAt(cell[0], cell[1])
+		assert.False(t, ok, cell)
+	}
+}
+
+func TestChoose(t *testing.T) {
+	m := newTestModel("4k3/P7/8/8/8/8/4P3/4K3 w - - 0 1", player.NewHumanPlayer("white"), player.NewHumanPlayer("black"))
+
+	// empty squares and pieces without moves can't be picked up
+	next, cmd := m.choose(bitboard.ParseSquare("e4"))
+	assert.Nil(t, cmd)
+	assert.Equal(t, bitboard.NoSquare, next.(model).selected)
+
+	// choosing a piece twice puts it down again
+	next, _ = m.choose(bitboard.ParseSquare("e2"))
+	m = next.(model)
+	assert.Equal(t, bitboard.ParseSquare("e2"), m.selected)
+	next, _ = m.choose(bitboard.ParseSquare("e2"))
+	assert.Equal(t, bitboard.NoSquare, next.(model).selected)
+
+	// choosing another piece picks that one up instead
+	next, _ = m.choose(bitboard.ParseSquare("a7"))
+	m = next.(model)
+	assert.Equal(t, bitboard.ParseSquare("a7"), m.selected)
+
+	next, cmd = m.choose(bitboard.ParseSquare("e3"))
+	assert.Nil(t, cmd)
+	m = next.(model)
+	assert.Equal(t, bitboard.NoSquare, m.selected)
+}
+
+func TestPromotionPicker(t *testing.T) {
+	m := newTestModel("4k3/P7/8/8/8/8/8/4K3 w - - 0 1", player.NewHumanPlayer("white"), player.NewHumanPlayer("black"))
+
+	next, _ := m.choose(bitboard.ParseSquare("a7"))
+	next, cmd := next.(model).choose(bitboard.ParseSquare("a8"))
+	assert.Nil(t, cmd)
+	m = next.(model)
+
+	var offered []piece.Piece
+	for _, mv := range m.promotions {
+		offered = append(offered, mv.Promotion)
+	}
+	assert.Equal(t, []piece.Piece{piece.Queen, piece.Rook, piece.Bishop, piece.Knight}, offered)
+
+	tests := map[string]struct {
+		keys     []tea.Msg
+		expected piece.Piece
+	}{
+		"enter takes the queen": {
+			keys:     []tea.Msg{tea.KeyMsg{Type: tea.KeyEnter}},
+			expected: piece.Queen,
+		},
+		"arrows move along the pieces": {
+			keys:     []tea.Msg{tea.KeyMsg{Type: tea.KeyRight}, tea.KeyMsg{Type: tea.KeyRight}, tea.KeyMsg{Type: tea.KeyLeft}, tea.KeyMsg{Type: tea.KeyEnter}},
+			expected: piece.Rook,
+		},
+		"a piece's letter": {
+			keys:     []tea.Msg{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")}},
+			expected: piece.Knight,
+		},
+		"a click on a piece": {
+			keys:     []tea.Msg{tea.MouseMsg{X: 9, Y: pickerTop, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft}},
+			expected: piece.Bishop,
+		},
+		"esc cancels": {
+			keys: []tea.Msg{tea.KeyMsg{Type: tea.KeyEsc}},
+		},
+		"a click elsewhere cancels": {
+			keys: []tea.Msg{tea.MouseMsg{X: 2, Y: 1, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			picker := tea.Model(m)
+			var cmd tea.Cmd
+			for _, key := range test.keys {
+				picker, cmd = picker.Update(key)
+			}
+
+			assert.False(t, picker.(model).promoting())
+			if test.expected == piece.Empty {
+				assert.Nil(t, cmd)
+				return
+			}
+
+			if assert.NotNil(t, cmd) {
+				assert.Equal(t, move.NewPromotionMove("a7", "a8", test.expected), cmd())
+			}
+		})
+	}
+}
+
+func TestEnterMove(t *testing.T) {
+	tests := map[string]struct {
+		fen       string
+		input     string
+		expected  move.Move
+		err       error
+		promoting bool
+	}{
+		"long algebraic": {
+			fen:      board.StartingFEN,
+			input:    "g1f3",
+			expected: move.NewMove("g1", "f3"),
+		},
+		"SAN": {
+			fen:      board.StartingFEN,
+			input:    "Nf3",
+			expected: move.NewMove("g1", "f3"),
+		},
+		"castling": {
+			fen:      "4k3/8/8/8/8/8/8/4K2R w K - 0 1",
+			input:    "O-O",
+			expected: move.NewMove("e1", "g1"),
+		},
+		"capturing promotion": {
+			fen:      "3rk3/4P3/8/8/8/8/8/4K3 w - - 0 1",
+			input:    "exd8=N",
+			expected: move.NewPromotionMove("e7", "d8", piece.Knight),
+		},
+		"promotion without a piece opens the picker": {
+			fen:       "3rk3/4P3/8/8/8/8/8/4K3 w - - 0 1",
+			input:     "exd8",
+			promoting: true,
+		},
+		"lowercase piece": {
+			fen:      board.StartingFEN,
+			input:    "nf3",
+			expected: move.NewMove("g1", "f3"),
+		},
+		"lowercase castling": {
+			fen:      "4k3/8/8/8/8/8/8/4K2R w K - 0 1",
+			input:    "o-o",
+			expected: move.NewMove("e1", "g1"),
+		},
+		"lowercase b is a pawn when it can be": {
+			fen:      "4k3/8/8/8/8/2p5/1P1B4/4K3 w - - 0 1",
+			input:    "bxc3",
+			expected: move.NewMove("b2", "c3"),
+		},
+		"lowercase b is a bishop when no pawn can": {
+			fen:      "4k3/8/8/8/8/2p5/3B4/4K3 w - - 0 1",
+			input:    "bxc3",
+			expected: move.NewMove("d2", "c3"),
+		},
+		"ambiguous": {
+			fen:   "4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1",
+			input: "Nd2",
+			err:   state.ErrAmbiguousSAN,
+		},
+		"illegal": {
+			fen:   board.StartingFEN,
+			input: "Nf4",
+			err:   state.ErrIllegalSAN,
+		},
+		"not a move": {
+			fen:   board.StartingFEN,
+			input: "hello",
+			err:   state.ErrInvalidSAN,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newTestModel(test.fen, player.NewHumanPlayer("white"), player.NewHumanPlayer("black"))
+			m.input.SetValue(test.input)
+
+			next, cmd := m.onEnter()
+			m = next.(model)
+			assert.Equal(t, test.promoting, m.promoting())
+
+			switch {
+			case test.err != nil:
+				assert.Nil(t, cmd)
+				assert.ErrorIs(t, m.inputErr, test.err)
+				// the move is kept to be fixed
+				assert.Equal(t, test.input, m.input.Value())
+			case test.promoting:
+				assert.Nil(t, cmd)
+			default:
+				assert.NoError(t, m.inputErr)
+				if assert.NotNil(t, cmd) {
+					assert.Equal(t, test.expected, cmd())
+				}
+			}
+		})
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	m := newTestModel("4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", player.NewHumanPlayer("white"), player.NewHumanPlayer("black"))
+
+	// case doesn't matter
+	m.input.SetValue("n")
+	assert.ElementsMatch(t, []string{"Na3", "Nc3", "Nbd2", "Nfd2", "Ne3", "Ng3", "Nh2"}, m.completions())
+
+	m.input.SetValue("Nf")
+	assert.Equal(t, []string{"Nfd2"}, m.completions())
+
+	m.input.SetValue("")
+	assert.Empty(t, m.completions())
+}
+
+func TestBoardKeys(t *testing.T) {
+	m := newTestModel(board.StartingFEN, player.NewHumanPlayer("white"), player.NewHumanPlayer("black"))
+	assert.Equal(t, "e1", bitboard.SquareName(m.cursor))
+
+	press := func(keys ...tea.KeyMsg) tea.Cmd {
+		var cmd tea.Cmd
+		for _, key := range keys {
+			var next tea.Model
+			next, cmd = m.Update(key)
+			m = next.(model)
+		}
+		return cmd
+	}
+
+	k := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")}
+	l := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	// the cursor stops at the edge of the board
+	press(down, k, l)
+	assert.Equal(t, "f2", bitboard.SquareName(m.cursor))
+
+	press(enter)
+	assert.Equal(t, bitboard.ParseSquare("f2"), m.selected)
+
+	cmd := press(k, k, enter)
+	if assert.NotNil(t, cmd) {
+		assert.Equal(t, move.NewMove("f2", "f4"), cmd())
+	}
+
+	// while typing, letters go to the input instead of moving the cursor
+	press(tea.KeyMsg{Type: tea.KeyTab}, k)
+	assert.Equal(t, "f4", bitboard.SquareName(m.cursor))
+	assert.Equal(t, "k", m.input.Value())
+}
+
+func TestMoveEndsGame(t *testing.T) {
+	human := player.NewHumanPlayer("human")
+
+	tests := map[string]struct {
+		fen     string
+		variant state.Variant
+		move    move.Move
+		over    bool
+	}{
+		"checkmate": {
+			fen:  "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1",
+			move: move.NewMove("a1", "a8"),
+			over: true,
+		},
+		"stalemate": {
+			fen:  "7k/8/5Q2/8/8/8/8/6K1 w - - 0 1",
+			move: move.NewMove("f6", "f7"),
+			over: true,
+		},
+		"king in the center": {
+			fen:     "4k3/8/8/8/8/3K4/8/8 w - - 0 1",
+			variant: state.KingOfTheHill,
+			move:    move.NewMove("d3", "d4"),
+			over:    true,
+		},
+		"exploded king": {
+			fen:     "3qk3/8/8/8/8/8/8/3QK3 w - - 0 1",
+			variant: state.Atomic,
+			move:    move.NewMove("d1", "d8"),
+			over:    true,
+		},
+		"still playing": {
+			fen:  board.StartingFEN,
+			move: move.NewMove("e2", "e4"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var opts []func(*state.FENOptions)
+			if test.variant != nil {
+				opts = append(opts, state.WithVariant(test.variant))
+			}
+			m := initialModel(state.StartingStateFromFEN(test.fen, human, human, opts...))
+
+			_, cmd := m.onMove(test.move)
+			if !test.over {
+				assert.Nil(t, cmd)
+				return
+			}
+
+			if assert.NotNil(t, cmd) {
+				assert.Equal(t, tea.Quit(), cmd())
+			}
+		})
+	}
+}
@@ -0,0 +1,402 @@
+package uci
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethansaxenian/chess/board"
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/piece"
+	"github.com/ethansaxenian/chess/player"
+	"github.com/ethansaxenian/chess/state"
+)
+
+const (
+	engineName   = "chess"
+	engineAuthor = "Ethan Saxenian"
+)
+
+type Option struct {
+	Name    string
+	Type    string
+	Default string
+	Min     int
+	Max     int
+	Vars    []string
+}
+
+func (o Option) String() string {
+	repr := fmt.Sprintf("option name %s type %s", o.Name, o.Type)
+
+	if o.Type != "button" {
+		repr += fmt.Sprintf(" default %s", o.Default)
+	}
+
+	if o.Type == "spin" {
+		repr += fmt.Sprintf(" min %d max %d", o.Min, o.Max)
+	}
+
+	for _, v := range o.Vars {
+		repr += fmt.Sprintf(" var %s", v)
+	}
+
+	return repr
+}
+
+// Configurable players advertise options that can be changed with setoption.
+type Configurable interface {
+	Options() []Option
+	SetOption(name, value string) error
+}
+
+type Limits struct {
+	WTime, BTime time.Duration
+	WInc, BInc   time.Duration
+	MoveTime     time.Duration
+	MovesToGo    int
+	Depth        int
+	Nodes        int
+	Infinite     bool
+}
+
+// how many more moves a game is assumed to last when go doesn't say
+const defaultMovesToGo = 30
+
+// moveTime is how long to think for color: movetime when it's given, or else
+// a share of its clock plus the increment. 0 is no limit
+func (l Limits) moveTime(color piece.Piece) time.Duration {
+	if l.MoveTime > 0 {
+		return l.MoveTime
+	}
+
+	left, inc := l.WTime, l.WInc
+	if color == piece.Black {
+		left, inc = l.BTime, l.BInc
+	}
+	if left <= 0 {
+		return 0
+	}
+
+	movesToGo := l.MovesToGo
+	if movesToGo <= 0 {
+		movesToGo = defaultMovesToGo
+	}
+
+	// never the whole clock, since answering takes time too
+	return min(left/time.Duration(movesToGo)+inc, left*9/10)
+}
+
+type Server struct {
+	player   player.Player
+	state    *state.State
+	out      io.Writer
+	chess960 bool
+	variant  state.Variant
+
+	mu     sync.Mutex
+	done   chan struct{}
+	cancel context.CancelFunc
+}
+
+func NewServer(p player.Player) *Server {
+	return &Server{player: p}
+}
+
+func (s *Server) send(format string, args ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fmt.Fprintf(s.out, format+"\n", args...)
+}
+
+func (s *Server) Run(in io.Reader, out io.Writer) error {
+	s.out = out
+	s.newGame()
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch cmd, args := fields[0], fields[1:]; cmd {
+		case "uci":
+			s.onUCI()
+		case "isready":
+			s.send("readyok")
+		case "ucinewgame":
+			s.stopSearch()
+			s.newGame()
+			if r, ok := s.player.(player.GameResetter); ok {
+				r.NewGame()
+			}
+		case "setoption":
+			s.onSetOption(args)
+		case "position":
+			s.stopSearch()
+			s.onPosition(args)
+		case "go":
+			s.stopSearch()
+			s.onGo(args)
+		case "stop":
+			s.stopSearch()
+		case "quit":
+			s.stopSearch()
+			return nil
+		case "debug", "register", "ponderhit":
+			continue
+		default:
+			s.send("info string unknown command: %s", cmd)
+		}
+	}
+
+	s.stopSearch()
+	return scanner.Err()
+}
+
+func (s *Server) onUCI() {
+	s.send("id name %s", engineName)
+	s.send("id author %s", engineAuthor)
+
+	if c, ok := s.player.(Configurable); ok {
+		for _, o := range c.Options() {
+			s.send("%s", o)
+		}
+	}
+	s.send("%s", chess960Option)
+	s.send("%s", variantOption)
+
+	s.send("uciok")
+}
+
+// chess960Option is handled by the server rather than the player, since it
+// changes how positions and castling moves are read and written
+var chess960Option = Option{Name: "UCI_Chess960", Type: "check", Default: "false"}
+
+// variantOption is handled by the server too, since the state plays the rules
+var variantOption = func() Option {
+	o := Option{Name: "UCI_Variant", Type: "combo", Default: state.Standard.String()}
+	for _, v := range state.Variants {
+		o.Vars = append(o.Vars, v.String())
+	}
+
+	return o
+}()
+
+func (s *Server) fenOptions() []func(*state.FENOptions) {
+	opts := []func(*state.FENOptions){state.WithVariant(s.variant)}
+	if s.chess960 {
+		opts = append(opts, state.WithChess960())
+	}
+
+	return opts
+}
+
+func (s *Server) newGame() {
+	s.state = state.StartingStateFromFEN(board.StartingFEN, s.player, s.player, s.fenOptions()...)
+}
+
+func (s *Server) onSetOption(args []string) {
+	var name, value []string
+	var curr *[]string
+	for _, arg := range args {
+		switch arg {
+		case "name":
+			curr = &name
+		case "value":
+			curr = &value
+		default:
+			if curr != nil {
+				*curr = append(*curr, arg)
+			}
+		}
+	}
+
+	switch {
+	case strings.EqualFold(strings.Join(name, " "), chess960Option.Name):
+		on, err := strconv.ParseBool(strings.Join(value, " "))
+		if err != nil {
+			s.send("info string invalid value for %s: %s", chess960Option.Name, strings.Join(value, " "))
+			return
+		}
+
+		s.chess960 = on
+		s.newGame()
+		return
+	case strings.EqualFold(strings.Join(name, " "), variantOption.Name):
+		v, err := state.ParseVariant(strings.Join(value, " "))
+		if err != nil {
+			s.send("info string invalid value for %s: %s", variantOption.Name, strings.Join(value, " "))
+			return
+		}
+
+		s.variant = v
+		s.newGame()
+		return
+	}
+
+	c, ok := s.player.(Configurable)
+	if !ok {
+		s.send("info string unknown option: %s", strings.Join(name, " "))
+		return
+	}
+
+	if err := c.SetOption(strings.Join(name, " "), strings.Join(value, " ")); err != nil {
+		s.send("info string %v", err)
+	}
+}
+
+func (s *Server) onPosition(args []string) {
+	if len(args) == 0 {
+		return
+	}
+
+	var fen string
+	var rest []string
+	switch args[0] {
+	case "startpos":
+		fen = board.StartingFEN
+		rest = args[1:]
+	case "fen":
+		i := slices.Index(args, "moves")
+		if i == -1 {
+			i = len(args)
+		}
+		fen = strings.Join(args[1:i], " ")
+		rest = args[i:]
+	default:
+		s.send("info string invalid position: %s", strings.Join(args, " "))
+		return
+	}
+
+	st, err := state.ParseFEN(fen, append(s.fenOptions(), state.WithLenientFEN(), state.WithLegalPosition())...)
+	if err != nil {
+		s.send("info string %v", err)
+		return
+	}
+	st.Players[piece.White] = s.player
+	st.Players[piece.Black] = s.player
+	s.state = st
+
+	if len(rest) == 0 || rest[0] != "moves" {
+		return
+	}
+
+	for _, arg := range rest[1:] {
+		m, err := move.ParseMove(arg)
+		if err != nil || !slices.Contains(s.state.GeneratePossibleMoves(), m) {
+			s.send("info string illegal move: %s", arg)
+			return
+		}
+
+		s.state.MakeMove(m)
+	}
+}
+
+func parseLimits(args []string) Limits {
+	var l Limits
+
+	durations := map[string]*time.Duration{
+		"wtime":    &l.WTime,
+		"btime":    &l.BTime,
+		"winc":     &l.WInc,
+		"binc":     &l.BInc,
+		"movetime": &l.MoveTime,
+	}
+	counts := map[string]*int{
+		"movestogo": &l.MovesToGo,
+		"depth":     &l.Depth,
+		"nodes":     &l.Nodes,
+	}
+
+	for i := 0; i < len(args); i++ {
+		if args[i] == "infinite" {
+			l.Infinite = true
+			continue
+		}
+
+		if i+1 >= len(args) {
+			break
+		}
+
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil {
+			continue
+		}
+
+		if d, ok := durations[args[i]]; ok {
+			*d = time.Duration(n) * time.Millisecond
+			i++
+		} else if c, ok := counts[args[i]]; ok {
+			*c = n
+			i++
+		}
+	}
+
+	return l
+}
+
+func (s *Server) onGo(args []string) {
+	limits := parseLimits(args)
+	moveTime := limits.moveTime(s.state.ActiveColor)
+
+	if l, ok := s.player.(player.SearchLimiter); ok {
+		searchLimits := player.SearchLimits{Depth: limits.Depth, Nodes: limits.Nodes, MoveTime: moveTime}
+		if limits.Infinite {
+			searchLimits = player.SearchLimits{Infinite: true}
+		}
+		l.SetSearchLimits(searchLimits)
+	}
+
+	validMoves := s.state.GeneratePossibleMoves()
+	st := *s.state
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if moveTime > 0 && !limits.Infinite {
+		ctx, cancel = context.WithTimeout(context.Background(), moveTime)
+	}
+	done := make(chan struct{})
+	s.done, s.cancel = done, cancel
+
+	go func() {
+		defer close(done)
+
+		var best move.Move
+		if len(validMoves) > 0 {
+			var err error
+			best, err = st.ActivePlayerMove(ctx, validMoves)
+			if err != nil {
+				s.send("info string %v", err)
+			}
+		}
+
+		// an infinite search must not report until it is stopped
+		if limits.Infinite {
+			<-ctx.Done()
+		}
+
+		if best == (move.Move{}) {
+			s.send("bestmove 0000")
+		} else {
+			s.send("bestmove %s", best)
+		}
+	}()
+}
+
+func (s *Server) stopSearch() {
+	if s.done == nil {
+		return
+	}
+
+	s.cancel()
+	<-s.done
+	s.done, s.cancel = nil, nil
+}
@@ -0,0 +1,283 @@
+package uci
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethansaxenian/chess/move"
+	"github.com/ethansaxenian/chess/player"
+	"github.com/stretchr/testify/assert"
+)
+
+type firstMovePlayer struct {
+	options map[string]string
+}
+
+func (f *firstMovePlayer) GetMove(ctx context.Context, game player.GameView) (move.Move, error) {
+	return game.ValidMoves()[0], nil
+}
+
+func (f *firstMovePlayer) IsBot() bool {
+	return true
+}
+
+func (f *firstMovePlayer) Options() []Option {
+	return []Option{{Name: "Skill Level", Type: "spin", Default: "1", Min: 0, Max: 20}}
+}
+
+func (f *firstMovePlayer) SetOption(name, value string) error {
+	if name != "Skill Level" {
+		return errors.New("unknown option: " + name)
+	}
+	f.options[name] = value
+	return nil
+}
+
+func run(t *testing.T, p *firstMovePlayer, input ...string) []string {
+	var out bytes.Buffer
+	err := NewServer(p).Run(strings.NewReader(strings.Join(input, "\n")), &out)
+	assert.NoError(t, err)
+	return strings.Split(strings.TrimSpace(out.String()), "\n")
+}
+
+func TestHandshake(t *testing.T) {
+	p := &firstMovePlayer{options: map[string]string{}}
+	out := run(t, p, "uci", "setoption name Skill Level value 5", "isready", "quit")
+	assert.Equal(t, []string{
+		"id name chess",
+		"id author Ethan Saxenian",
+		"option name Skill Level type spin default 1 min 0 max 20",
+		"option name UCI_Chess960 type check default false",
+		"option name UCI_Variant type combo default chess var chess var crazyhouse var 3check var kingofthehill var atomic var antichess",
+		"uciok",
+		"readyok",
+	}, out)
+	assert.Equal(t, "5", p.options["Skill Level"])
+}
+
+func TestPositionAndGo(t *testing.T) {
+	p := &firstMovePlayer{}
+
+	out := run(t, p, "position startpos moves e2e4 e7e5", "go wtime 1000 btime 1000 winc 10 binc 10")
+	assert.Equal(t, []string{"bestmove a2a3"}, out)
+
+	out = run(t, p, "position fen 7k/P7/8/8/8/8/8/7K w - - 0 1", "go depth 3")
+	assert.Equal(t, []string{"bestmove a7a8b"}, out)
+
+	out = run(t, p, "position fen 7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", "go movetime 100")
+	assert.Equal(t, []string{"bestmove 0000"}, out)
+}
+
+func TestChess960(t *testing.T) {
+	p := &firstMovePlayer{}
+	position := "position fen rk5r/8/8/8/8/8/8/RK5R w KQkq - 0 1 moves b1h1 b8a8"
+
+	out := run(t, p, "setoption name UCI_Chess960 value true", position, "go depth 1")
+	assert.Equal(t, []string{"bestmove a1a2"}, out)
+
+	out = run(t, p, position, "go depth 1")
+	assert.Contains(t, out[0], "castling needs the white king on its home square")
+}
+
+func TestVariant(t *testing.T) {
+	p := &firstMovePlayer{}
+	position := "position startpos moves e2e4 d7d5"
+
+	// captures are compulsory in antichess
+	out := run(t, p, "setoption name UCI_Variant value antichess", position, "go depth 1")
+	assert.Equal(t, []string{"bestmove e4d5"}, out)
+
+	out = run(t, p, position, "go depth 1")
+	assert.Equal(t, []string{"bestmove a2a3"}, out)
+
+	out = run(t, p, "setoption name UCI_Variant value shogi", position, "go depth 1")
+	assert.Equal(t, []string{"info string invalid value for UCI_Variant: shogi", "bestmove a2a3"}, out)
+}
+
+func TestInvalidPositionKeepsRunning(t *testing.T) {
+	p := &firstMovePlayer{}
+
+	out := run(t, p, "position fen 7k/P7/8 w - - 0 1", "position startpos", "go depth 1")
+	assert.Len(t, out, 2)
+	assert.Contains(t, out[0], "info string invalid FEN")
+	assert.Equal(t, "bestmove a2a3", out[1])
+
+	out = run(t, p, "position fen 8/8/8/8/8/8/8/8 w - -", "position fen 7k/P7/8/8/8/8/8/7K w -  -", "go depth 1")
+	assert.Len(t, out, 2)
+	assert.Contains(t, out[0], "white has 0 kings")
+	assert.Equal(t, "bestmove a7a8b", out[1])
+}
+
+func TestGoInfiniteWaitsForStop(t *testing.T) {
+	p := &firstMovePlayer{}
+	s := NewServer(p)
+
+	inR, inW := io.Pipe()
+	var out safeBuffer
+	go s.Run(inR, &out)
+
+	io.WriteString(inW, "position startpos\ngo infinite\n")
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, out.String())
+
+	io.WriteString(inW, "stop\n")
+	assert.Eventually(t, func() bool { return out.String() == "bestmove a2a3\n" }, time.Second, 10*time.Millisecond)
+	inW.Close()
+}
+
+// thinkingPlayer never finds a move on its own
+type thinkingPlayer struct{}
+
+func (thinkingPlayer) GetMove(ctx context.Context, game player.GameView) (move.Move, error) {
+	<-ctx.Done()
+	return move.Move{}, ctx.Err()
+}
+
+func (thinkingPlayer) IsBot() bool {
+	return true
+}
+
+func TestStopCancelsSearch(t *testing.T) {
+	s := NewServer(thinkingPlayer{})
+
+	inR, inW := io.Pipe()
+	var out safeBuffer
+	go s.Run(inR, &out)
+
+	io.WriteString(inW, "position startpos\ngo\n")
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, out.String())
+
+	io.WriteString(inW, "stop\n")
+	assert.Eventually(t, func() bool {
+		return out.String() == "info string context canceled\nbestmove 0000\n"
+	}, time.Second, 10*time.Millisecond)
+	inW.Close()
+}
+
+// limitedPlayer remembers how it was told to search
+type limitedPlayer struct {
+	firstMovePlayer
+	limits   player.SearchLimits
+	deadline time.Duration
+	newGames int
+}
+
+func (l *limitedPlayer) GetMove(ctx context.Context, game player.GameView) (move.Move, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		l.deadline = time.Until(deadline)
+	}
+	return l.firstMovePlayer.GetMove(ctx, game)
+}
+
+func (l *limitedPlayer) SetSearchLimits(limits player.SearchLimits) {
+	l.limits = limits
+}
+
+func (l *limitedPlayer) NewGame() {
+	l.newGames++
+}
+
+func TestGoLimits(t *testing.T) {
+	tests := map[string]struct {
+		position string
+		goCmd    string
+		expected player.SearchLimits
+	}{
+		"move time": {
+			position: "position startpos",
+			goCmd:    "go movetime 100",
+			expected: player.SearchLimits{MoveTime: 100 * time.Millisecond},
+		},
+		"depth and nodes": {
+			position: "position startpos",
+			goCmd:    "go depth 3 nodes 5000",
+			expected: player.SearchLimits{Depth: 3, Nodes: 5000},
+		},
+		"a share of white's clock": {
+			position: "position startpos",
+			goCmd:    "go wtime 60000 btime 1000 winc 1000 binc 0",
+			expected: player.SearchLimits{MoveTime: 3 * time.Second},
+		},
+		"a share of black's clock": {
+			position: "position startpos moves e2e4",
+			goCmd:    "go wtime 60000 btime 20000 winc 0 binc 0 movestogo 10",
+			expected: player.SearchLimits{MoveTime: 2 * time.Second},
+		},
+		"never the whole clock": {
+			position: "position startpos",
+			goCmd:    "go wtime 1000 winc 5000",
+			expected: player.SearchLimits{MoveTime: 900 * time.Millisecond},
+		},
+		"no limits": {
+			position: "position startpos",
+			goCmd:    "go",
+		},
+		"infinite ignores the clock": {
+			position: "position startpos",
+			goCmd:    "go infinite wtime 60000 btime 60000",
+			expected: player.SearchLimits{Infinite: true},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := &limitedPlayer{}
+
+			var buf bytes.Buffer
+			err := NewServer(p).Run(strings.NewReader(test.position+"\n"+test.goCmd), &buf)
+			assert.NoError(t, err)
+
+			assert.Equal(t, test.expected, p.limits)
+			if test.expected.MoveTime > 0 {
+				assert.InDelta(t, test.expected.MoveTime, p.deadline, float64(50*time.Millisecond))
+			} else {
+				assert.Zero(t, p.deadline)
+			}
+		})
+	}
+}
+
+func TestNewGameResetsPlayer(t *testing.T) {
+	p := &limitedPlayer{}
+
+	var out bytes.Buffer
+	err := NewServer(p).Run(strings.NewReader("ucinewgame\nposition startpos\nucinewgame"), &out)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, p.newGames)
+}
+
+func TestParseLimits(t *testing.T) {
+	l := parseLimits(strings.Fields("wtime 300000 btime 290000 winc 2000 binc 2000 movestogo 40 depth 6"))
+	assert.Equal(t, Limits{
+		WTime:     300 * time.Second,
+		BTime:     290 * time.Second,
+		WInc:      2 * time.Second,
+		BInc:      2 * time.Second,
+		MovesToGo: 40,
+		Depth:     6,
+	}, l)
+}
+
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}